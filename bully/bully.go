@@ -0,0 +1,391 @@
+// Package bully implements the Bully algorithm for electing a leader among nodes.
+// Every node has an id, and the node with the highest id that is alive becomes the leader.
+//
+// A node that notices there is no leader starts an election, by sending an Election message to
+// every node with a higher id. If any of them Answer, they take over the election, otherwise the node
+// has the highest id alive and tells everyone with a Coordinator message that it is the leader.
+//
+// The leader sends a Heartbeat to everyone, and a node that doesn't hear from it for a while starts an election.
+// A node that comes back with a higher id than the leader starts an election too, and bullies its way to leader.
+package bully
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	gRPC "github.com/PatrickMatthiesen/DSYS-gRPC-template/proto"
+
+	"google.golang.org/grpc"
+)
+
+type Peer struct {
+	ID   int64
+	Addr string
+}
+
+// Leader is the node that won the last election.
+type Leader Peer
+
+type Config struct {
+	Name  string // used in the log, if Logger is nil
+	ID    int64  // the id of this node, the highest one alive becomes the leader
+	Addr  string // the address the other nodes can reach this node at
+	Peers []Peer // the other nodes
+
+	HeartbeatInterval time.Duration // how often the leader sends heartbeats (default 1s)
+	FailureTimeout    time.Duration // how long without a heartbeat before the leader is taken to be gone (default 3s)
+	AnswerTimeout     time.Duration // how long to wait for an answer from the nodes with a higher id (default 1s)
+
+	DialOptions []grpc.DialOption // used when dialing the peers
+
+	Logger *slog.Logger // where the node logs to (nil = slog.Default(), with the name of the node)
+}
+
+type Node struct {
+	cfg    Config
+	logger *slog.Logger
+
+	mu            sync.Mutex
+	leader        Leader
+	hasLeader     bool
+	election      *election // the election we are running, nil if there is none
+	lastHeartbeat time.Time // when we last heard from the leader
+	sent          int64     // the election messages we have sent, not counting heartbeats
+	sentBefore    int64     // sent when the messages were last counted for an election, see electionMessages
+
+	changes chan Leader
+
+	clientsMu sync.Mutex
+	clients   map[string]gRPC.BullyClient
+
+	stop chan struct{}
+}
+
+// election is an election this node has started.
+type election struct {
+	answered chan struct{} // closed when a node with a higher id answers
+	decided  chan struct{} // closed when a leader is announced
+}
+
+func New(cfg Config) *Node {
+	if cfg.HeartbeatInterval == 0 {
+		cfg.HeartbeatInterval = time.Second
+	}
+	if cfg.FailureTimeout == 0 {
+		cfg.FailureTimeout = 3 * time.Second
+	}
+	if cfg.AnswerTimeout == 0 {
+		cfg.AnswerTimeout = time.Second
+	}
+	return &Node{
+		cfg:     cfg,
+		logger:  newLogger(cfg.Logger, cfg.Name),
+		changes: make(chan Leader, 1),
+		clients: make(map[string]gRPC.BullyClient),
+		stop:    make(chan struct{}),
+	}
+}
+
+// Start starts an election, as the node doesn't know of a leader yet, and starts the heartbeats.
+func (n *Node) Start() {
+	n.startElection("started")
+	go n.run()
+}
+
+// Stop stops the node. It stops sending heartbeats, if it is the leader, so the others elect a new one.
+func (n *Node) Stop() {
+	close(n.stop)
+}
+
+// Leader returns the current leader, and false if there is none, like during an election.
+func (n *Node) Leader() (Leader, bool) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.leader, n.hasLeader
+}
+
+// IsLeader reports whether this node is the leader.
+func (n *Node) IsLeader() bool {
+	leader, ok := n.Leader()
+	return ok && leader.ID == n.cfg.ID
+}
+
+// Changes returns a channel that gets the new leader every time it changes.
+// If the leader changes again before it is read, only the newest one is kept, so the reader is never behind.
+func (n *Node) Changes() <-chan Leader {
+	return n.changes
+}
+
+// run sends heartbeats while we are the leader, and starts an election if we don't hear from the leader.
+func (n *Node) run() {
+	ticker := time.NewTicker(n.cfg.HeartbeatInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-n.stop:
+			return
+		case <-ticker.C:
+		}
+
+		n.mu.Lock()
+		isLeader := n.hasLeader && n.leader.ID == n.cfg.ID
+		gone := !isLeader && n.election == nil && time.Since(n.lastHeartbeat) > n.cfg.FailureTimeout
+		n.mu.Unlock()
+
+		switch {
+		case isLeader:
+			n.broadcast(n.cfg.Peers, false, func(ctx context.Context, c gRPC.BullyClient, msg *gRPC.BullyMessage) error {
+				_, err := c.Heartbeat(ctx, msg)
+				return err
+			})
+		case gone:
+			n.startElection("the leader is gone")
+		}
+	}
+}
+
+// startElection starts an election, unless we are running one already.
+func (n *Node) startElection(reason string) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.election != nil {
+		return
+	}
+	n.logger.Info("starting an election", "reason", reason)
+	n.hasLeader = false
+	n.election = &election{answered: make(chan struct{}), decided: make(chan struct{})}
+	go n.elect(n.election)
+}
+
+// elect runs the election e: it asks the nodes with higher ids to take over, and becomes the leader if none of them answer.
+func (n *Node) elect(e *election) {
+	var higher []Peer
+	for _, p := range n.cfg.Peers {
+		if p.ID > n.cfg.ID {
+			higher = append(higher, p)
+		}
+	}
+	n.broadcast(higher, true, func(ctx context.Context, c gRPC.BullyClient, msg *gRPC.BullyMessage) error {
+		_, err := c.Election(ctx, msg)
+		return err
+	})
+
+	select {
+	case <-e.answered:
+	case <-time.After(n.cfg.AnswerTimeout):
+		n.becomeLeader(e)
+		return
+	case <-n.stop:
+		return
+	}
+
+	// a node with a higher id took over, so we wait for it to announce the winner.
+	// if nobody does, the one that answered died in the meantime, and we start over
+	select {
+	case <-e.decided:
+	case <-time.After(n.cfg.FailureTimeout):
+		n.mu.Lock()
+		if n.election == e {
+			n.election = nil
+		}
+		n.mu.Unlock()
+		n.startElection("nobody announced a leader")
+	case <-n.stop:
+	}
+}
+
+// becomeLeader makes us the leader, and tells everyone, if e is still the election we are running.
+func (n *Node) becomeLeader(e *election) {
+	n.mu.Lock()
+	if n.election != e {
+		n.mu.Unlock()
+		return
+	}
+	n.election = nil
+	messages := n.electionMessages()
+	n.setLeader(Leader{ID: n.cfg.ID, Addr: n.cfg.Addr})
+	n.mu.Unlock()
+
+	// every node answers the coordinator message with the messages it sent in the election,
+	// so we can tell how many the election took in all
+	var others atomic.Int64
+	delivered := n.broadcast(n.cfg.Peers, true, func(ctx context.Context, c gRPC.BullyClient, msg *gRPC.BullyMessage) error {
+		ack, err := c.Coordinator(ctx, msg)
+		others.Add(ack.GetMessages())
+		return err
+	})
+	n.mu.Lock()
+	messages += n.electionMessages() // the coordinator messages
+	n.mu.Unlock()
+	n.logger.Info("the election is over", "messages", messages+others.Load(), "nodes", delivered+1)
+}
+
+// electionMessages returns the election messages we have sent since it was last called, and starts counting again.
+// The caller must hold n.mu.
+func (n *Node) electionMessages() int64 {
+	messages := n.sent - n.sentBefore
+	n.sentBefore = n.sent
+	return messages
+}
+
+// setLeader makes leader the leader, and tells the reader of Changes. The caller must hold n.mu.
+func (n *Node) setLeader(leader Leader) {
+	n.lastHeartbeat = time.Now()
+	if n.hasLeader && n.leader == leader {
+		return
+	}
+	n.leader, n.hasLeader = leader, true
+	n.logger.Info("new leader", "leader", leader.ID, "addr", leader.Addr)
+
+	// replaces the leader that hasn't been read yet, if there is one
+	select {
+	case <-n.changes:
+	default:
+	}
+	n.changes <- leader
+}
+
+// coordinator handles a Coordinator or Heartbeat from the node in msg, which says it is the leader.
+func (n *Node) coordinator(msg *gRPC.BullyMessage) {
+	if msg.Id < n.cfg.ID {
+		// we have a higher id, so we should be the leader
+		n.startElection("a node with a lower id is the leader")
+		return
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.election != nil {
+		close(n.election.decided)
+		n.election = nil
+	}
+	n.setLeader(Leader{ID: msg.Id, Addr: msg.Addr})
+}
+
+// broadcast calls call on every peer in peers, at the same time, and waits for them to finish.
+// A peer that doesn't answer within the answer timeout is taken to be down, so the call isn't retried.
+// Election messages are counted, heartbeats aren't. It returns how many of the peers the call got to.
+func (n *Node) broadcast(peers []Peer, count bool, call func(context.Context, gRPC.BullyClient, *gRPC.BullyMessage) error) int64 {
+	var delivered atomic.Int64
+	var wg sync.WaitGroup
+	for _, p := range peers {
+		wg.Add(1)
+		go func(p Peer) {
+			defer wg.Done()
+			if n.send(p.Addr, count, call) {
+				delivered.Add(1)
+			}
+		}(p)
+	}
+	wg.Wait()
+	return delivered.Load()
+}
+
+// send calls call on the node at addr, once, and reports whether it got there.
+// With count the message is counted if it did, as a message to a node that is down is never sent.
+func (n *Node) send(addr string, count bool, call func(context.Context, gRPC.BullyClient, *gRPC.BullyMessage) error) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), n.cfg.AnswerTimeout)
+	defer cancel()
+	msg := &gRPC.BullyMessage{Id: n.cfg.ID, Addr: n.cfg.Addr}
+	if err := call(ctx, n.client(addr), msg); err != nil {
+		n.logger.Debug("failed to reach peer", "peer", addr, "err", err)
+		return false
+	}
+	if count {
+		n.mu.Lock()
+		n.sent++
+		n.mu.Unlock()
+	}
+	return true
+}
+
+// server handles the Bully RPCs for a node.
+type server struct {
+	gRPC.UnimplementedBullyServer
+	n *Node
+}
+
+// Register registers the node's Bully service on s.
+func (n *Node) Register(s *grpc.Server) {
+	gRPC.RegisterBullyServer(s, &server{n: n})
+}
+
+// Election answers a node with a lower id, and takes over its election.
+func (s *server) Election(ctx context.Context, msg *gRPC.BullyMessage) (*gRPC.BullyAck, error) {
+	n := s.n
+	n.logger.Debug("got an election message", "from", msg.Id)
+	go n.send(msg.Addr, true, func(ctx context.Context, c gRPC.BullyClient, msg *gRPC.BullyMessage) error {
+		_, err := c.Answer(ctx, msg)
+		return err
+	})
+	n.startElection("a node with a lower id started one")
+	return &gRPC.BullyAck{}, nil
+}
+
+// Answer notes that a node with a higher id has taken over our election.
+func (s *server) Answer(ctx context.Context, msg *gRPC.BullyMessage) (*gRPC.BullyAck, error) {
+	n := s.n
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if e := n.election; e != nil {
+		select {
+		case <-e.answered:
+		default:
+			n.logger.Debug("a node with a higher id took over the election", "from", msg.Id)
+			close(e.answered)
+		}
+	}
+	return &gRPC.BullyAck{}, nil
+}
+
+// Coordinator notes the new leader, and tells it how many messages we sent in the election.
+func (s *server) Coordinator(ctx context.Context, msg *gRPC.BullyMessage) (*gRPC.BullyAck, error) {
+	n := s.n
+	n.mu.Lock()
+	messages := n.electionMessages()
+	n.mu.Unlock()
+	n.logger.Debug("sent in the election", "messages", messages)
+	n.coordinator(msg)
+	return &gRPC.BullyAck{Messages: messages}, nil
+}
+
+func (s *server) Heartbeat(ctx context.Context, msg *gRPC.BullyMessage) (*gRPC.BullyAck, error) {
+	s.n.coordinator(msg)
+	return &gRPC.BullyAck{}, nil
+}
+
+// client returns a client for peer, dialing it the first time.
+func (n *Node) client(peer string) gRPC.BullyClient {
+	n.clientsMu.Lock()
+	defer n.clientsMu.Unlock()
+
+	if c, ok := n.clients[peer]; ok {
+		return c
+	}
+	// without grpc.WithBlock this doesn't wait for the connection,
+	// so it only fails if the options are wrong
+	conn, err := grpc.Dial(peer, n.cfg.DialOptions...)
+	if err != nil {
+		n.fatal("failed to dial peer", "peer", peer, "err", err)
+	}
+	c := gRPC.NewBullyClient(conn)
+	n.clients[peer] = c
+	return c
+}
+
+func newLogger(logger *slog.Logger, name string) *slog.Logger {
+	if logger == nil {
+		logger = slog.Default().With("node", name)
+	}
+	return logger.With("component", "bully")
+}
+
+// fatal logs msg and stops the program.
+func (n *Node) fatal(msg string, args ...any) {
+	n.logger.Error(msg, args...)
+	os.Exit(1)
+}
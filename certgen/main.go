@@ -0,0 +1,46 @@
+// certgen makes a throwaway certificate authority and certificates for the servers and clients,
+// so a local cluster can run over TLS without openssl.
+//
+//	go run ./certgen -names server,alice,bob
+//
+// writes ca.pem, ca-key.pem and a <name>.pem and <name>-key.pem for every name to the "certs" folder.
+// Run it again with more names to add certificates signed by the same CA.
+package main
+
+import (
+	"flag"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/PatrickMatthiesen/DSYS-gRPC-template/tlsutil"
+)
+
+var out = flag.String("out", "certs", "Folder to write the certificates to")
+var names = flag.String("names", "server", "Comma separated list of names to make certificates for, the name becomes the identity of the certificate")
+var hosts = flag.String("hosts", "localhost,127.0.0.1", "Comma separated list of the hosts and IPs the certificates are valid for")
+var validFor = flag.Duration("valid-for", 30*24*time.Hour, "How long the certificates are valid")
+
+func main() {
+	flag.Parse()
+
+	// reuse the CA if there already is one, so old certificates still work
+	ca, err := tlsutil.LoadCA(*out)
+	if err != nil {
+		log.Printf("No CA in %s, making a new one", *out)
+		if ca, err = tlsutil.GenerateCA(*out, *validFor); err != nil {
+			log.Fatalf("Failed to make the CA: %v", err)
+		}
+	}
+
+	for _, name := range strings.Split(*names, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if err := ca.GenerateCert(*out, name, strings.Split(*hosts, ","), *validFor); err != nil {
+			log.Fatalf("Failed to make the certificate for %s: %v", name, err)
+		}
+		log.Printf("Made %s/%s.pem and %s/%s-key.pem", *out, name, *out, name)
+	}
+}
@@ -0,0 +1,421 @@
+// Package chandylamport takes consistent snapshots of a group of nodes with the Chandy–Lamport algorithm:
+// the state of every node, and the messages that were on their way between them, as if taken at one point in time.
+//
+// Every node has a channel to every other node, and the messages on a channel arrive in the order they were sent.
+// A node starts a snapshot by recording its own state, and sending a marker on every channel. A node that gets
+// a marker for the first time records its state, and sends a marker on every channel too. After recording its state,
+// a node records the messages it gets on every other channel until the marker comes on that channel too, those are
+// the messages that were on their way. When a node has the marker from every channel, it is done, and it sends its
+// part of the snapshot to the node that started it.
+package chandylamport
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+
+	gRPC "github.com/PatrickMatthiesen/DSYS-gRPC-template/proto"
+
+	"google.golang.org/grpc"
+)
+
+// ErrStopped is returned by Send and Snapshot when the node is stopped.
+var ErrStopped = errors.New("chandylamport: stopped")
+
+type Config struct {
+	Name  string   // used in the log, if Logger is nil
+	Addr  string   // the address the other nodes can reach this node at, it is also the id of the node
+	Peers []string // the other nodes, every one of them has a channel to and from this node
+
+	// State returns the state of the node as JSON, when it takes its part of a snapshot.
+	// It is called with the node locked, so no message is sent or delivered at the same time.
+	State func() []byte
+	// Deliver is called with every message from another node, in the order they were sent.
+	// It is called with the node locked too, so it must not call Send.
+	Deliver func(from string, payload []byte)
+
+	RetryInterval time.Duration     // how long to wait before sending a message again to a node that didn't answer
+	DialOptions   []grpc.DialOption // used when dialing the peers
+
+	Logger *slog.Logger // where the node logs to (nil = slog.Default(), with the name of the node)
+}
+
+// Global is a snapshot of every node.
+type Global struct {
+	ID        string            `json:"id"`
+	Initiator string            `json:"initiator"` // the node that started it
+	Nodes     map[string]*Local `json:"nodes"`
+}
+
+// Local is the part of a snapshot from one node.
+type Local struct {
+	State    json.RawMessage              `json:"state"`
+	Channels map[string][]json.RawMessage `json:"channels"` // the messages that were on their way to the node, by the node that sent them
+}
+
+type Node struct {
+	cfg    Config
+	logger *slog.Logger
+	epoch  int64 // when the node started, so the others can tell it has restarted
+
+	mu    sync.Mutex
+	out   map[string]*channel  // the channels to the other nodes
+	in    map[string]*received // the last message from each of the other nodes
+	snaps map[string]*snap     // the snapshots in progress, by id
+
+	clientsMu sync.Mutex
+	clients   map[string]gRPC.GlobalSnapshotClient
+
+	stop chan struct{}
+}
+
+// channel is the channel to another node. The messages are sent one at a time, in order.
+type channel struct {
+	peer  string
+	seq   uint64 // the seq of the last message queued, guarded by Node.mu
+	mu    sync.Mutex
+	queue []*gRPC.ChannelMessage
+	wake  chan struct{} // gets a value when a message is queued
+}
+
+type received struct {
+	epoch int64
+	seq   uint64
+}
+
+// snap is a snapshot this node is taking its part of.
+type snap struct {
+	id        string
+	initiator string
+	state     []byte
+	recording map[string]bool     // the channels we haven't had the marker from yet
+	channels  map[string][][]byte // the messages recorded on each channel
+	reported  bool
+
+	// only on the node that started it
+	global *Global
+	done   chan struct{} // closed when every node has reported
+}
+
+func New(cfg Config) *Node {
+	if cfg.RetryInterval == 0 {
+		cfg.RetryInterval = time.Second
+	}
+	n := &Node{
+		cfg:     cfg,
+		logger:  newLogger(cfg.Logger, cfg.Name),
+		epoch:   time.Now().UnixNano(),
+		out:     make(map[string]*channel),
+		in:      make(map[string]*received),
+		snaps:   make(map[string]*snap),
+		clients: make(map[string]gRPC.GlobalSnapshotClient),
+		stop:    make(chan struct{}),
+	}
+	for _, peer := range cfg.Peers {
+		n.out[peer] = &channel{peer: peer, wake: make(chan struct{}, 1)}
+	}
+	return n
+}
+
+// Start starts sending the messages on the channels to the other nodes.
+func (n *Node) Start() {
+	for _, c := range n.out {
+		go n.sendLoop(c)
+	}
+}
+
+// Stop stops the node. The messages that haven't been sent yet are lost.
+// When it returns, no Send is in progress, so the state it changes can be closed.
+func (n *Node) Stop() {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	close(n.stop)
+}
+
+// Send sends payload, which must be JSON, to peer. apply makes the change to the state of this node that goes with
+// the message, like taking out the amount that is sent. It is called with the node locked, so a snapshot either has
+// the change and the message, or neither of them. If apply fails, the message isn't sent.
+func (n *Node) Send(peer string, payload []byte, apply func() error) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	c, ok := n.out[peer]
+	if !ok {
+		return fmt.Errorf("chandylamport: %s isn't a peer", peer)
+	}
+	select {
+	case <-n.stop:
+		return ErrStopped
+	default:
+	}
+	if err := apply(); err != nil {
+		return err
+	}
+	n.enqueue(c, &gRPC.ChannelMessage{Payload: payload})
+	return nil
+}
+
+// Snapshot takes a snapshot of every node, and waits for all of them to report their part.
+// If ctx is done first, like when a node is down, it gives up and returns ctx.Err().
+func (n *Node) Snapshot(ctx context.Context) (*Global, error) {
+	n.mu.Lock()
+	id := fmt.Sprintf("%d-%s", time.Now().UnixNano(), n.cfg.Addr)
+	s := &snap{
+		id:        id,
+		initiator: n.cfg.Addr,
+		global:    &Global{ID: id, Initiator: n.cfg.Addr, Nodes: make(map[string]*Local)},
+		done:      make(chan struct{}),
+	}
+	n.logger.Info("starting a snapshot", "id", id)
+	n.record(s, "")
+	n.mu.Unlock()
+
+	defer func() {
+		n.mu.Lock()
+		delete(n.snaps, id)
+		n.mu.Unlock()
+	}()
+	select {
+	case <-s.done:
+		return s.global, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-n.stop:
+		return nil, ErrStopped
+	}
+}
+
+// record records the state of this node for s, sends a marker on every channel,
+// and starts recording the channels, except the one the marker came on. The caller must hold n.mu.
+func (n *Node) record(s *snap, except string) {
+	s.state = n.cfg.State()
+	s.recording = make(map[string]bool)
+	s.channels = make(map[string][][]byte)
+	for _, peer := range n.cfg.Peers {
+		s.channels[peer] = nil
+		if peer != except {
+			s.recording[peer] = true
+		}
+		n.enqueue(n.out[peer], &gRPC.ChannelMessage{Marker: s.id, Initiator: s.initiator})
+	}
+	n.snaps[s.id] = s
+	n.checkDone(s)
+}
+
+// checkDone reports our part of s, if we have the marker from every channel. The caller must hold n.mu.
+func (n *Node) checkDone(s *snap) {
+	if len(s.recording) > 0 || s.reported {
+		return
+	}
+	s.reported = true
+
+	local := &gRPC.LocalSnapshot{Id: s.id, Node: n.cfg.Addr, State: s.state}
+	for peer, messages := range s.channels {
+		local.Channels = append(local.Channels, &gRPC.ChannelState{From: peer, Messages: messages})
+	}
+	n.logger.Debug("done with our part of the snapshot", "id", s.id)
+
+	if s.initiator == n.cfg.Addr {
+		n.collect(s, local)
+		return
+	}
+	delete(n.snaps, s.id)
+	go n.report(s.initiator, local)
+}
+
+// collect adds the part of s from one node, on the node that started it. The caller must hold n.mu.
+func (n *Node) collect(s *snap, local *gRPC.LocalSnapshot) {
+	part := &Local{State: json.RawMessage(local.State), Channels: make(map[string][]json.RawMessage)}
+	for _, c := range local.Channels {
+		messages := make([]json.RawMessage, 0, len(c.Messages))
+		for _, m := range c.Messages {
+			messages = append(messages, json.RawMessage(m))
+		}
+		part.Channels[c.From] = messages
+	}
+	s.global.Nodes[local.Node] = part
+
+	if len(s.global.Nodes) == len(n.cfg.Peers)+1 {
+		n.logger.Info("snapshot done", "id", s.id)
+		close(s.done)
+	}
+}
+
+// report sends our part of a snapshot to the node that started it, until it gets there.
+func (n *Node) report(initiator string, local *gRPC.LocalSnapshot) {
+	for {
+		ctx, cancel := context.WithTimeout(context.Background(), n.cfg.RetryInterval)
+		_, err := n.client(initiator).Report(ctx, local)
+		cancel()
+		if err == nil {
+			return
+		}
+		n.logger.Debug("failed to report the snapshot, trying again", "initiator", initiator, "err", err)
+
+		select {
+		case <-n.stop:
+			return
+		case <-time.After(n.cfg.RetryInterval):
+		}
+	}
+}
+
+// enqueue queues msg on c, with the next seq. The caller must hold n.mu, so the messages are queued
+// in the same order as the changes to the state they go with.
+func (n *Node) enqueue(c *channel, msg *gRPC.ChannelMessage) {
+	c.seq++
+	msg.From, msg.Epoch, msg.Seq = n.cfg.Addr, n.epoch, c.seq
+
+	c.mu.Lock()
+	c.queue = append(c.queue, msg)
+	c.mu.Unlock()
+	select {
+	case c.wake <- struct{}{}:
+	default:
+	}
+}
+
+// sendLoop sends the messages queued on c, one at a time, so they arrive in order.
+// A message that fails is sent again until it gets there, the seq makes sure it is only delivered once.
+func (n *Node) sendLoop(c *channel) {
+	for {
+		c.mu.Lock()
+		var msg *gRPC.ChannelMessage
+		if len(c.queue) > 0 {
+			msg = c.queue[0]
+		}
+		c.mu.Unlock()
+
+		if msg == nil {
+			select {
+			case <-c.wake:
+				continue
+			case <-n.stop:
+				return
+			}
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), n.cfg.RetryInterval)
+		_, err := n.client(c.peer).Deliver(ctx, msg)
+		cancel()
+		if err == nil {
+			c.mu.Lock()
+			c.queue = c.queue[1:]
+			c.mu.Unlock()
+			continue
+		}
+		n.logger.Debug("failed to send message, trying again", "peer", c.peer, "seq", msg.Seq, "err", err)
+
+		select {
+		case <-n.stop:
+			return
+		case <-time.After(n.cfg.RetryInterval):
+		}
+	}
+}
+
+// server handles the GlobalSnapshot RPCs for a node.
+type server struct {
+	gRPC.UnimplementedGlobalSnapshotServer
+	n *Node
+}
+
+// Register registers the node's GlobalSnapshot service on s.
+func (n *Node) Register(s *grpc.Server) {
+	gRPC.RegisterGlobalSnapshotServer(s, &server{n: n})
+}
+
+// Deliver takes a message from the channel from another node.
+func (s *server) Deliver(ctx context.Context, msg *gRPC.ChannelMessage) (*gRPC.ChannelAck, error) {
+	n := s.n
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	// a message we already have was sent again, because the answer got lost
+	last, ok := n.in[msg.From]
+	if !ok || last.epoch != msg.Epoch {
+		last = &received{epoch: msg.Epoch}
+		n.in[msg.From] = last
+	}
+	if msg.Seq <= last.seq {
+		return &gRPC.ChannelAck{}, nil
+	}
+	last.seq = msg.Seq
+
+	if msg.Marker != "" {
+		n.marker(msg.From, msg.Marker, msg.Initiator)
+		return &gRPC.ChannelAck{}, nil
+	}
+
+	n.cfg.Deliver(msg.From, msg.Payload)
+	for _, snap := range n.snaps {
+		if snap.recording[msg.From] {
+			snap.channels[msg.From] = append(snap.channels[msg.From], msg.Payload)
+		}
+	}
+	return &gRPC.ChannelAck{}, nil
+}
+
+// marker handles the marker for snapshot id from the channel from from. The caller must hold n.mu.
+func (n *Node) marker(from, id, initiator string) {
+	s, ok := n.snaps[id]
+	switch {
+	case ok:
+		// the messages on the channel from before the marker are the ones that were on their way
+		delete(s.recording, from)
+		n.checkDone(s)
+	case initiator == n.cfg.Addr:
+		// we started it, but gave up on it
+	default:
+		n.record(&snap{id: id, initiator: initiator}, from)
+	}
+}
+
+// Report takes the part of a snapshot from another node, on the node that started it.
+func (s *server) Report(ctx context.Context, local *gRPC.LocalSnapshot) (*gRPC.ChannelAck, error) {
+	n := s.n
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if snap, ok := n.snaps[local.Id]; ok && snap.global != nil && snap.global.Nodes[local.Node] == nil {
+		n.collect(snap, local)
+	}
+	return &gRPC.ChannelAck{}, nil
+}
+
+// client returns a client for peer, dialing it the first time.
+func (n *Node) client(peer string) gRPC.GlobalSnapshotClient {
+	n.clientsMu.Lock()
+	defer n.clientsMu.Unlock()
+
+	if c, ok := n.clients[peer]; ok {
+		return c
+	}
+	// without grpc.WithBlock this doesn't wait for the connection,
+	// so it only fails if the options are wrong
+	conn, err := grpc.Dial(peer, n.cfg.DialOptions...)
+	if err != nil {
+		n.fatal("failed to dial peer", "peer", peer, "err", err)
+	}
+	c := gRPC.NewGlobalSnapshotClient(conn)
+	n.clients[peer] = c
+	return c
+}
+
+func newLogger(logger *slog.Logger, name string) *slog.Logger {
+	if logger == nil {
+		logger = slog.Default().With("node", name)
+	}
+	return logger.With("component", "chandylamport")
+}
+
+// fatal logs msg and stops the program.
+func (n *Node) fatal(msg string, args ...any) {
+	n.logger.Error(msg, args...)
+	os.Exit(1)
+}
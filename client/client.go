@@ -3,76 +3,151 @@ package main
 import (
 	"bufio"
 	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
 	"flag"
 	"fmt"
-	"log"
+	"io"
+	"log/slog"
 	"os"
 	"strconv"
 	"strings"
 
+	"github.com/PatrickMatthiesen/DSYS-gRPC-template/auth"
+	"github.com/PatrickMatthiesen/DSYS-gRPC-template/lamport"
+	"github.com/PatrickMatthiesen/DSYS-gRPC-template/logging"
+	"github.com/PatrickMatthiesen/DSYS-gRPC-template/tracing"
+	"github.com/PatrickMatthiesen/DSYS-gRPC-template/vclock"
 	// this has to be the same as the go.mod module,
 	// followed by the path to the folder the proto file is in.
 	gRPC "github.com/PatrickMatthiesen/DSYS-gRPC-template/proto"
 
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/connectivity"
+	_ "google.golang.org/grpc/health" // turns on the health checks from the service config
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
 )
 
 // Same principle as in client. Flags allows for user specific arguments/values
 var clientsName = flag.String("name", "default", "Senders name")
-var serverPort = flag.String("server", "5400", "Tcp server")
+var serverPort = flag.String("server", "5400", "Comma separated list of servers to use, as ports or host:port")
+var token = flag.String("token", "", "Bearer token for servers that require one, made with tokengen")
 
 var server gRPC.TemplateClient  //the server
 var ServerConn *grpc.ClientConn //the server connection
 
+// the logical time of the client, the interceptors in ConnectToServer keep it up to date
+var clock lamport.Clock
+var vectorClock *vclock.Clock // made in main, as it needs the name of the client
+
+// clientID and seq let the server see when an increment is a retry of one it already has.
+// The name isn't enough, as two clients can have the same one.
+var clientID string
+var seq uint64
+
+//log to file instead of console with "-log-file log.txt", see the logging package for the rest of the flags
+var logConfig = logging.RegisterFlags(flag.CommandLine)
+
 func main() {
 	//parse flag/arguments
 	flag.Parse()
 
 	fmt.Println("--- CLIENT APP ---")
 
-	//log to file instead of console
-	//f := setLog()
-	//defer f.Close()
+	//every log line gets the name of the client and the lamport time
+	_, logFile, err := logging.Setup(logConfig, clock.Now, "node", *clientsName)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	defer logFile.Close()
+	vectorClock = vclock.NewClock(*clientsName)
+	clientID = newClientID()
+	startTracing()
 
 	//connect to server and close the connection when program closes
 	fmt.Println("--- join Server ---")
-	ConnectToServer()
+	parseServers()
+	if err := reconnect(); err != nil {
+		logging.Fatal("could not connect to the server", "err", err)
+	}
 	defer ServerConn.Close()
 
 	//start the biding
 	parseInput()
 }
 
-// connect to server
-func ConnectToServer() {
+// errNotServing is returned by ConnectToServer when the server is up but doesn't take calls,
+// like a backup or a server that is shutting down.
+var errNotServing = errors.New("the server is not serving")
+
+// connect to server, giving up after "-timeout"
+func ConnectToServer() error {
 
 	//dial options
-	//the server is not using TLS, so we use insecure credentials
+	//without the TLS flags we use insecure credentials, see tls.go
 	//(should be fine for local testing but not in the real world)
+	//the interceptors stamp every message with the lamport time and send the vector clock along,
+	//with "-trace-file" the ones from tracingOptions trace the call too
+	//the resolver and service config tell gRPC which servers it can use and how to pick between them,
+	//with "-lb round_robin" servers that fail their health check are skipped
 	opts := []grpc.DialOption {
 		grpc.WithBlock(), 
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithTransportCredentials(transportCredentials()),
+		grpc.WithResolvers(serverResolver()),
+		grpc.WithDefaultServiceConfig(fmt.Sprintf(`{"loadBalancingConfig": [{"%s": {}}], "healthCheckConfig": {"serviceName": "%s"}}`,
+			*lbPolicy, gRPC.Template_ServiceDesc.ServiceName)),
+		grpc.WithChainUnaryInterceptor(
+			lamport.UnaryClientInterceptor(&clock),
+			vclock.UnaryClientInterceptor(vectorClock),
+		),
+		grpc.WithChainStreamInterceptor(
+			lamport.StreamClientInterceptor(&clock),
+			vclock.StreamClientInterceptor(vectorClock),
+		),
+	}
+	opts = append(opts, tracingOptions()...)
+	//the token from "-token" is sent with every call, for servers running with "-auth-secret"
+	if *token != "" {
+		opts = append(opts, grpc.WithPerRPCCredentials(auth.TokenCredentials(*token)))
 	}
 
 	//dial the server, with the flag "server", to get a connection to it
-	log.Printf("client %s: Attempts to dial on port %s\n", *clientsName, *serverPort)
-	conn, err := grpc.Dial(fmt.Sprintf(":%s", *serverPort), opts...)
+	//grpc.WithBlock makes it wait for the connection, so we give up if it takes too long
+	slog.Info("dialing", "servers", strings.Join(servers, ","))
+	ctx, cancel := context.WithTimeout(context.Background(), *callTimeout)
+	defer cancel()
+	conn, err := grpc.DialContext(ctx, "servers:///template", opts...)
 	if err != nil {
-		log.Printf("Fail to Dial : %v", err)
-		return
+		slog.Warn("failed to dial", "err", err)
+		return err
+	}
+	//the connection being up doesn't mean the server takes calls, so we ask it once before using it
+	if !conReady(conn) {
+		conn.Close()
+		slog.Warn("the server is not serving")
+		return errNotServing
 	}
 
 	// makes a client from the server connection and saves the connection
 	// and prints rather or not the connection was is READY
 	server = gRPC.NewTemplateClient(conn)
 	ServerConn = conn
-	log.Println("the connection is: ", conn.GetState().String())
+	slog.Info("connected", "state", conn.GetState().String())
+	return nil
 }
 
 func parseInput() {
 	reader := bufio.NewReader(os.Stdin)
 	fmt.Println("Type the amount you wish to increment with here. Type 0 to get the current value")
+	fmt.Println("Or use a named counter: \"inc <key> <n>\", \"get <key>\", \"del <key>\", \"reset <key>\" or \"list [prefix]\"")
+	fmt.Println("Change it only if nobody else has: \"cas <key> <expected> <new>\" or \"incif <key> <version> <n>\"")
+	fmt.Println("Type \"watch [prefix]\" to see the changes to the counters as they happen")
+	fmt.Println("Type \"snapshot\" to take a snapshot of the counters on all the servers at once")
+	fmt.Println("Type \"hi\" to say hi to the server, or \"chat\" to chat with the other clients")
 	fmt.Println("--------------------")
 
 	//Infinite loop to listen for clients input.
@@ -82,56 +157,118 @@ func parseInput() {
 		//Read input into var input and any errors into err
 		input, err := reader.ReadString('\n')
 		if err != nil {
-			log.Fatal(err)
+			logging.Fatal("failed to read input", "err", err)
 		}
 		input = strings.TrimSpace(input) //Trim input
 
-		if !conReady(server) {
-			log.Printf("Client %s: something was wrong with the connection to the server :(", *clientsName)
+		//Convert string to int64, return error if the int is larger than 32bit or not a number
+		val, err := strconv.ParseInt(input, 10, 64)
+		if err == nil {
+			incrementVal("", val) //a plain number increments the default counter
 			continue
 		}
 
-		//Convert string to int64, return error if the int is larger than 32bit or not a number
-		val, err := strconv.ParseInt(input, 10, 64)
-		if err != nil {
-			if input == "hi" {
-				sayHi()
-			}
+		//the other commands are a word followed by their arguments
+		args := strings.Fields(input)
+		if len(args) == 0 {
 			continue
 		}
-		incrementVal(val)
+		switch {
+		case args[0] == "hi":
+			sayHi()
+		case args[0] == "chat":
+			chat(reader)
+		case args[0] == "inc" && len(args) == 3:
+			val, err := strconv.ParseInt(args[2], 10, 64)
+			if err != nil {
+				fmt.Println("Usage: inc <key> <n>, where n is a number")
+				continue
+			}
+			incrementVal(args[1], val)
+		case (args[0] == "cas" || args[0] == "incif") && len(args) == 4:
+			a, errA := strconv.ParseInt(args[2], 10, 64)
+			b, errB := strconv.ParseInt(args[3], 10, 64)
+			if errA != nil || errB != nil || (args[0] == "incif" && a < 0) {
+				fmt.Println("Usage: cas <key> <expected> <new> or incif <key> <version> <n>, with numbers")
+				continue
+			}
+			if args[0] == "cas" {
+				compareAndSet(args[1], a, b)
+			} else {
+				incrementIf(args[1], uint64(a), b)
+			}
+		case args[0] == "get" && len(args) == 2:
+			getCounter(args[1])
+		case args[0] == "del" && len(args) == 2:
+			deleteCounter(args[1])
+		case args[0] == "reset" && len(args) == 2:
+			resetCounter(args[1])
+		case args[0] == "list" && len(args) <= 2:
+			listCounters(strings.Join(args[1:], ""))
+		case args[0] == "watch" && len(args) <= 2:
+			watch(reader, strings.Join(args[1:], ""))
+		case args[0] == "snapshot" && len(args) == 1:
+			takeSnapshot()
+		default:
+			fmt.Println("Unknown command, see the list above")
+		}
 	}
 }
 
-func incrementVal(val int64) {
-	//create amount type
+// incrementVal increments the counter with key by val, "" is the default counter
+func incrementVal(key string, val int64) {
+	//create amount type, every increment gets the next seq
+	seq++
 	amount := &gRPC.Amount{
 		ClientName: *clientsName,
+		Key:        key,
 		Value:      val, //cast from int to int32
+		ClientId:   clientID,
+		Seq:        seq,
 	}
 
 	//Make gRPC call to server with amount, and recieve acknowlegdement back.
-	ack, err := server.Increment(context.Background(), amount)
+	//Every retry sends the same seq, so the server only adds the value once
+	//even if it got the increment the first time.
+	var ack *gRPC.Ack
+	err := retry("increment", true, func(ctx context.Context) error {
+		var err error
+		ack, err = server.Increment(ctx, amount)
+		return err
+	})
 	if err != nil {
-		log.Printf("Client %s: no response from the server, attempting to reconnect", *clientsName)
-		log.Println(err)
+		reportError("the increment failed", err, "key", key, "value", val)
+		return
 	}
 
-	// check if the server has handled the request correctly
-	if ack.NewValue >= val {
+	// without an error the increment went through. The new value says nothing more,
+	// as the amount can be negative and the other clients increment too.
+	if key == "" {
 		fmt.Printf("Success, the new value is now %d\n", ack.NewValue)
 	} else {
-		// something could be added here to handle the error
-		// but hopefully this will never be reached
-		fmt.Println("Oh no something went wrong :(")
+		fmt.Printf("Success, the new value of %s is now %d\n", key, ack.NewValue)
 	}
 }
 
+// newClientID makes a random id for this run of the client
+func newClientID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		logging.Fatal("failed to make a client id", "err", err)
+	}
+	return *clientsName + "-" + hex.EncodeToString(b)
+}
+
 func sayHi() {
-	// get a stream to the server
-	stream, err := server.SayHi(context.Background())
+	// get a stream to the server, which has to be done before the deadline
+	ctx, span := tracer.Start(context.Background(), "sayHi", tracing.KindInternal)
+	defer span.End()
+	ctx, cancel := context.WithTimeout(ctx, *callTimeout)
+	defer cancel()
+	stream, err := server.SayHi(ctx)
 	if err != nil {
-		log.Println(err)
+		slog.ErrorContext(ctx, "failed to say hi", "err", err)
+		failoverIfUnavailable(err)
 		return
 	}
 
@@ -143,23 +280,83 @@ func sayHi() {
 	// close the stream
 	farewell, err := stream.CloseAndRecv()
 	if err != nil {
-		log.Println(err)
+		slog.ErrorContext(ctx, "failed to say hi", "err", err)
+		failoverIfUnavailable(err)
 		return
 	}
-	log.Println("server says: ", farewell)
+	slog.InfoContext(ctx, "server says goodbye", "message", farewell.GetMessage())
 }
 
-// Function which returns a true boolean if the connection to the server is ready, and false if it's not.
-func conReady(s gRPC.TemplateClient) bool {
-	return ServerConn.GetState().String() == "READY"
+// chat joins the chat and sends every line the user types to the other clients,
+// while messages from the others are printed as they arrive. Type /quit to leave.
+func chat(reader *bufio.Reader) {
+	stream, err := server.Chat(context.Background())
+	if err != nil {
+		slog.Error("failed to join the chat", "err", err)
+		failoverIfUnavailable(err)
+		return
+	}
+
+	// the first message tells the server who we are
+	if err := stream.Send(&gRPC.ChatMessage{ClientName: *clientsName, Kind: gRPC.ChatMessage_JOIN}); err != nil {
+		slog.Error("failed to join the chat", "err", err)
+		failoverIfUnavailable(err)
+		return
+	}
+	fmt.Println("--- You joined the chat, type /quit to leave ---")
+
+	// print messages from the others while the loop below waits for the user to type
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for {
+			msg, err := stream.Recv()
+			if err != nil {
+				if err != io.EOF {
+					slog.Warn("lost connection to the chat", "err", err)
+				}
+				return
+			}
+			// \r moves to the start of the line, so the message replaces the "-> " prompt
+			if msg.Kind == gRPC.ChatMessage_MESSAGE {
+				fmt.Printf("\r%s: %s\n-> ", msg.ClientName, msg.Message)
+			} else {
+				fmt.Printf("\r*** %s ***\n-> ", msg.Message)
+			}
+		}
+	}()
+
+	for {
+		fmt.Print("-> ")
+		input, err := reader.ReadString('\n')
+		input = strings.TrimSpace(input)
+		if err != nil || input == "/quit" {
+			break
+		}
+		if input == "" {
+			continue
+		}
+		if err := stream.Send(&gRPC.ChatMessage{ClientName: *clientsName, Message: input}); err != nil {
+			break // the goroutine above logs why
+		}
+	}
+
+	// tell the server we are leaving and wait for it to close the stream
+	stream.CloseSend()
+	<-done
+	fmt.Println("--- You left the chat ---")
 }
 
-// sets the logger to use a log.txt file instead of the console
-func setLog() *os.File {
-	f, err := os.OpenFile("log.txt", os.O_RDWR|os.O_CREATE|os.O_APPEND, 0666)
-	if err != nil {
-		log.Fatalf("error opening file: %v", err)
+// Function which returns a true boolean if the server is ready, and false if it's not.
+// It asks the server with the standard health check if it is serving the Template service,
+// as the connection can be fine while the server isn't, like when it is shutting down or is a backup.
+func conReady(conn *grpc.ClientConn) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), *callTimeout)
+	defer cancel()
+	resp, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{Service: gRPC.Template_ServiceDesc.ServiceName})
+	if status.Code(err) == codes.Unimplemented {
+		// an old server without health checks, so all we can go by is the connection
+		return conn.GetState() == connectivity.Ready
 	}
-	log.SetOutput(f)
-	return f
+	return err == nil && resp.GetStatus() == healthpb.HealthCheckResponse_SERVING
 }
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	gRPC "github.com/PatrickMatthiesen/DSYS-gRPC-template/proto"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// getCounter prints the value of the counter with key
+func getCounter(key string) {
+	var counter *gRPC.Counter
+	err := retry("get", true, func(ctx context.Context) error {
+		var err error
+		counter, err = server.Get(ctx, &gRPC.Key{ClientName: *clientsName, Key: key})
+		return err
+	})
+	if err != nil {
+		reportError("failed to get the counter", err, "key", key)
+		return
+	}
+	fmt.Printf("%s is %d (version %d)\n", counter.Key, counter.Value, counter.Version)
+}
+
+// listCounters prints every counter with a key starting with prefix
+func listCounters(prefix string) {
+	var list *gRPC.CounterList
+	err := retry("list", true, func(ctx context.Context) error {
+		var err error
+		list, err = server.List(ctx, &gRPC.ListRequest{ClientName: *clientsName, Prefix: prefix})
+		return err
+	})
+	if err != nil {
+		reportError("failed to list the counters", err)
+		return
+	}
+	if len(list.Counters) == 0 {
+		fmt.Println("There are no counters yet")
+	}
+	for _, counter := range list.Counters {
+		fmt.Printf("%s is %d (version %d)\n", counter.Key, counter.Value, counter.Version)
+	}
+}
+
+// deleteCounter removes the counter with key.
+// Deleting twice leaves the counter just as gone, so it is retried, but then the retry may get NOT_FOUND.
+func deleteCounter(key string) {
+	var counter *gRPC.Counter
+	err := retry("delete", true, func(ctx context.Context) error {
+		var err error
+		counter, err = server.Delete(ctx, &gRPC.Key{ClientName: *clientsName, Key: key})
+		return err
+	})
+	if err != nil {
+		reportError("failed to delete the counter", err, "key", key)
+		return
+	}
+	fmt.Printf("Deleted %s, it was %d\n", counter.Key, counter.Value)
+}
+
+// resetCounter sets the counter with key back to 0.
+// It isn't retried, as a retry could wipe out increments made in between.
+func resetCounter(key string) {
+	var counter *gRPC.Counter
+	err := retry("reset", false, func(ctx context.Context) error {
+		var err error
+		counter, err = server.Reset(ctx, &gRPC.Key{ClientName: *clientsName, Key: key})
+		return err
+	})
+	if err != nil {
+		reportError("failed to reset the counter", err, "key", key)
+		return
+	}
+	fmt.Printf("Reset %s, it was %d\n", counter.Key, counter.Value)
+}
+
+// compareAndSet sets the counter with key to new, if it is still expected.
+// A retry that comes after the first try went through fails the condition, so it never sets the counter twice.
+func compareAndSet(key string, expected, new int64) {
+	var counter *gRPC.Counter
+	err := retry("compareAndSet", true, func(ctx context.Context) error {
+		var err error
+		counter, err = server.CompareAndSet(ctx, &gRPC.CasRequest{ClientName: *clientsName, Key: key, Expected: expected, New: new})
+		return err
+	})
+	if conditionFailed(err) {
+		return
+	}
+	if err != nil {
+		reportError("compare and set failed", err, "key", key)
+		return
+	}
+	fmt.Printf("Success, %s is now %d (version %d)\n", counter.Key, counter.Value, counter.Version)
+}
+
+// incrementIf increments the counter with key by val, if it is still at version.
+// Like compareAndSet it is safe to retry, as the version has moved on if the first try went through.
+func incrementIf(key string, version uint64, val int64) {
+	var counter *gRPC.Counter
+	err := retry("incrementIf", true, func(ctx context.Context) error {
+		var err error
+		counter, err = server.IncrementIf(ctx, &gRPC.ConditionalAmount{ClientName: *clientsName, Key: key, Value: val, Version: version})
+		return err
+	})
+	if conditionFailed(err) {
+		return
+	}
+	if err != nil {
+		reportError("the conditional increment failed", err, "key", key)
+		return
+	}
+	fmt.Printf("Success, %s is now %d (version %d)\n", counter.Key, counter.Value, counter.Version)
+}
+
+// conditionFailed prints the current counter if err says the condition of a
+// compareAndSet or incrementIf didn't hold, so the user can try again with it.
+func conditionFailed(err error) bool {
+	st := status.Convert(err)
+	if st.Code() != codes.FailedPrecondition {
+		return false
+	}
+	for _, detail := range st.Details() {
+		if current, ok := detail.(*gRPC.Counter); ok {
+			fmt.Printf("Somebody changed %s first, it is %d (version %d)\n", current.Key, current.Value, current.Version)
+			return true
+		}
+	}
+	return false
+}
+
+// takeSnapshot has the server take a global snapshot, and prints the totals of the counters in it.
+// It isn't retried, as a retry would take another one.
+func takeSnapshot() {
+	var result *gRPC.SnapshotResult
+	err := retry("snapshot", false, func(ctx context.Context) error {
+		var err error
+		result, err = server.Snapshot(ctx, &gRPC.SnapshotRequest{ClientName: *clientsName})
+		return err
+	})
+	if err != nil {
+		reportError("failed to take the snapshot", err)
+		return
+	}
+	fmt.Printf("The server wrote the snapshot to %s, with %d transfers on their way\n", result.File, result.InFlight)
+	keys := make([]string, 0, len(result.Totals))
+	for key := range result.Totals {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		fmt.Printf("%s is %d in total\n", key, result.Totals[key])
+	}
+}
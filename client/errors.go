@@ -0,0 +1,30 @@
+package main
+
+import (
+	"log/slog"
+
+	gRPC "github.com/PatrickMatthiesen/DSYS-gRPC-template/proto"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/status"
+)
+
+// reportError logs that msg failed with err, along with the details the server sent,
+// like which field was wrong or what the counter is right now.
+func reportError(msg string, err error, args ...any) {
+	st := status.Convert(err)
+	args = append(args, "code", st.Code(), "err", st.Message())
+	for _, detail := range st.Details() {
+		switch d := detail.(type) {
+		case *errdetails.BadRequest:
+			for _, v := range d.GetFieldViolations() {
+				args = append(args, "field", v.GetField(), "problem", v.GetDescription())
+			}
+		case *errdetails.ErrorInfo:
+			args = append(args, "reason", d.GetReason())
+		case *gRPC.Counter:
+			args = append(args, "current_value", d.GetValue(), "current_version", d.GetVersion())
+		}
+	}
+	slog.Error(msg, args...)
+}
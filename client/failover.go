@@ -0,0 +1,64 @@
+package main
+
+import (
+	"flag"
+	"log/slog"
+	"net"
+	"strings"
+
+	"google.golang.org/grpc/resolver"
+	"google.golang.org/grpc/resolver/manual"
+)
+
+var lbPolicy = flag.String("lb", "pick_first", `How to pick between the servers in "-server": "pick_first" (use one until it fails) or "round_robin" (spread the calls)`)
+
+// servers holds the addresses from "-server", the one we try first is at the front.
+var servers []string
+
+// parseServers splits "-server" into host:port addresses. A lone port means a server on this machine,
+// so "-server 5400,5401" is the same as "-server localhost:5400,localhost:5401".
+func parseServers() {
+	servers = nil
+	for _, s := range strings.Split(*serverPort, ",") {
+		s = strings.TrimSpace(s)
+		if s == "" {
+			continue
+		}
+		if !strings.Contains(s, ":") {
+			s = "localhost:" + s
+		}
+		servers = append(servers, s)
+	}
+}
+
+// serverResolver makes a resolver that gives gRPC the addresses from "-server".
+// The balancer picked with "-lb" then decides which of them each call goes to.
+// Dial "servers:///template" with it, the part after the slashes doesn't matter.
+func serverResolver() *manual.Resolver {
+	r := manual.NewBuilderWithScheme("servers")
+	addrs := make([]resolver.Address, len(servers))
+	for i, s := range servers {
+		// with TLS the certificate of each server is checked against its own host, not "template"
+		host, _, _ := net.SplitHostPort(s)
+		addrs[i] = resolver.Address{Addr: s, ServerName: host}
+	}
+	r.InitialState(resolver.State{Addresses: addrs})
+	return r
+}
+
+// failover moves the server we are using to the back of the list and reconnects,
+// so pick_first starts with the next server instead of the one that just failed us.
+func failover() {
+	nextServer()
+	if err := reconnect(); err != nil {
+		slog.Error("could not connect to any server", "err", err)
+	}
+}
+
+// nextServer moves the server we are using to the back of the list.
+func nextServer() {
+	if len(servers) > 1 {
+		servers = append(servers[1:], servers[0])
+		slog.Warn("failing over", "server", servers[0])
+	}
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log/slog"
+	"math/rand"
+	"time"
+
+	"github.com/PatrickMatthiesen/DSYS-gRPC-template/tracing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var retries = flag.Int("retries", 3, "How many times to retry a call that is safe to retry")
+var callTimeout = flag.Duration("timeout", 5*time.Second, "Deadline for a single call to the server")
+var maxBackoff = flag.Duration("max-backoff", 10*time.Second, "The longest time to wait between two attempts")
+
+// the wait before the second attempt, it doubles for every attempt after that
+const baseBackoff = 250 * time.Millisecond
+
+// backoff returns how long to wait before attempt number n (counting from 0),
+// doubling every time up to "-max-backoff". The wait is random between half and all of that,
+// so clients that lost the server at the same time don't all come back at the same time.
+func backoff(attempt int) time.Duration {
+	d := *maxBackoff
+	if attempt < 30 && baseBackoff<<attempt < d {
+		d = baseBackoff << attempt
+	}
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+}
+
+// reconnect dials the server again, trying up to "-retries" more times if it fails or isn't serving,
+// and closes the old connection once the new one is up. If none of the attempts work it returns the last error,
+// and the old connection is kept, as gRPC keeps trying to connect it in the background.
+func reconnect() error {
+	old := ServerConn
+	var err error
+	for attempt := 0; ; attempt++ {
+		if err = ConnectToServer(); err == nil {
+			break
+		}
+		if attempt >= *retries {
+			return err
+		}
+		if err == errNotServing {
+			// it is up but won't take our calls, the next one might
+			nextServer()
+		}
+		wait := backoff(attempt)
+		slog.Warn("could not connect to the server, trying again", "err", err, "wait", wait.Round(time.Millisecond))
+		time.Sleep(wait)
+	}
+	if old != nil {
+		old.Close()
+	}
+	return nil
+}
+
+// retry calls call with a deadline of "-timeout". If call is idempotent (doing it twice is the same as
+// doing it once) and fails in a way that might go away, it is retried up to "-retries" times.
+// If the server is unavailable we fail over to the next one, whether the call is retried or not,
+// which also dials it again, so there is nothing more to do before the next attempt.
+// With tracing on, all the attempts are part of one span called what.
+func retry(what string, idempotent bool, call func(ctx context.Context) error) error {
+	parent, span := tracer.Start(context.Background(), what, tracing.KindInternal)
+	defer span.End()
+
+	for attempt := 0; ; attempt++ {
+		ctx, cancel := context.WithTimeout(parent, *callTimeout)
+		err := call(ctx)
+		cancel()
+
+		failoverIfUnavailable(err)
+		if err == nil || !idempotent || attempt >= *retries || !retryable(err) {
+			span.SetAttribute("attempts", attempt+1)
+			span.SetError(err)
+			return err
+		}
+
+		wait := backoff(attempt)
+		slog.WarnContext(parent, what+" failed, retrying", "code", status.Code(err), "wait", wait.Round(time.Millisecond))
+		time.Sleep(wait)
+	}
+}
+
+// failoverIfUnavailable fails over to the next server if err says the server is unavailable.
+// retry does it for every attempt, the streams that aren't made with retry call it themselves.
+func failoverIfUnavailable(err error) {
+	if status.Code(err) == codes.Unavailable {
+		failover()
+	}
+}
+
+// retryable reports whether err might go away if the call is tried again.
+func retryable(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded, codes.Aborted, codes.ResourceExhausted:
+		return true
+	default:
+		return false
+	}
+}
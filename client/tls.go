@@ -0,0 +1,31 @@
+package main
+
+import (
+	"flag"
+
+	"github.com/PatrickMatthiesen/DSYS-gRPC-template/logging"
+	"github.com/PatrickMatthiesen/DSYS-gRPC-template/tlsutil"
+
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// the client uses TLS when "-tls" or any of the other TLS flags are set.
+// "-tls-cert" and "-tls-key" are only needed when the server runs with "-mtls".
+var useTLS = flag.Bool("tls", false, "Connect to the server over TLS")
+var tlsCA = flag.String("tls-ca", "", "CA used to check the certificate of the server (empty = the CAs of the system)")
+var tlsCert = flag.String("tls-cert", "", "Certificate of the client, for servers that require one")
+var tlsKey = flag.String("tls-key", "", "Private key of the client certificate")
+var tlsServerName = flag.String("tls-server-name", "", "Name to check the server certificate against (empty = the host that is dialed)")
+
+// transportCredentials returns the credentials ConnectToServer dials with.
+func transportCredentials() credentials.TransportCredentials {
+	if !*useTLS && *tlsCA == "" && *tlsCert == "" {
+		return insecure.NewCredentials()
+	}
+	creds, err := tlsutil.ClientCredentials(*tlsCert, *tlsKey, *tlsCA, *tlsServerName)
+	if err != nil {
+		logging.Fatal("failed to set up TLS", "err", err)
+	}
+	return creds
+}
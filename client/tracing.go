@@ -0,0 +1,39 @@
+package main
+
+import (
+	"flag"
+
+	"github.com/PatrickMatthiesen/DSYS-gRPC-template/logging"
+	"github.com/PatrickMatthiesen/DSYS-gRPC-template/tracing"
+
+	"google.golang.org/grpc"
+)
+
+// set with "-trace-file traces.json", use the same file as the server to see both sides of every call together
+var traceFile = flag.String("trace-file", "", "File to append the spans of every call to, as JSON lines (empty = no tracing)")
+
+// the tracer of the client, nil when "-trace-file" isn't set, which turns tracing off
+var tracer *tracing.Tracer
+
+// startTracing makes the tracer, if "-trace-file" is set.
+func startTracing() {
+	if *traceFile == "" {
+		return
+	}
+	exporter, err := tracing.NewFileExporter(*traceFile)
+	if err != nil {
+		logging.Fatal("failed to open the trace file", "err", err)
+	}
+	tracer = tracing.NewTracer(*clientsName, exporter)
+}
+
+// tracingOptions returns the dial options that trace every call, if "-trace-file" is set.
+func tracingOptions() []grpc.DialOption {
+	if tracer == nil {
+		return nil
+	}
+	return []grpc.DialOption{
+		grpc.WithChainUnaryInterceptor(tracing.UnaryClientInterceptor(tracer)),
+		grpc.WithChainStreamInterceptor(tracing.StreamClientInterceptor(tracer)),
+	}
+}
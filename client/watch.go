@@ -0,0 +1,81 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	gRPC "github.com/PatrickMatthiesen/DSYS-gRPC-template/proto"
+
+	"google.golang.org/grpc/status"
+)
+
+// watchPosition is the last change a watcher got. The seq is only counted within the epoch,
+// so the server only resumes from it if the epoch is still the same.
+type watchPosition struct {
+	epoch, seq uint64
+}
+
+// watch prints every change to the counters with keys starting with prefix, until the user presses Enter.
+// If the stream breaks, like when the server shuts down or we fall behind,
+// it watches again from the last change it got, so none are missed.
+func watch(reader *bufio.Reader, prefix string) {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		var last watchPosition
+		for attempt := 0; ; attempt++ {
+			got, err := watchOnce(ctx, prefix, &last)
+			if ctx.Err() != nil {
+				return // the user stopped watching
+			}
+			if got {
+				attempt = 0
+			}
+			if !retryable(err) {
+				slog.Error("the watch failed", "err", err)
+				fmt.Print("\rPress Enter to go back\n-> ")
+				return
+			}
+			wait := backoff(attempt)
+			slog.Warn("lost the watch, watching again", "code", status.Code(err), "epoch", last.epoch, "after_seq", last.seq, "wait", wait.Round(time.Millisecond))
+			time.Sleep(wait)
+			failoverIfUnavailable(err)
+		}
+	}()
+
+	fmt.Println("--- Watching, press Enter to stop ---")
+	reader.ReadString('\n')
+	cancel()
+	<-done
+	fmt.Println("--- Stopped watching ---")
+}
+
+// watchOnce watches until the stream breaks, and keeps the position of the last change it got in last.
+// got tells if any changes came, so the caller knows the connection worked.
+func watchOnce(ctx context.Context, prefix string, last *watchPosition) (got bool, err error) {
+	stream, err := server.Watch(ctx, &gRPC.WatchRequest{ClientName: *clientsName, Prefix: prefix, Epoch: last.epoch, AfterSeq: last.seq})
+	if err != nil {
+		return false, err
+	}
+	for {
+		ev, err := stream.Recv()
+		if err != nil {
+			return got, err
+		}
+		got, *last = true, watchPosition{epoch: ev.Epoch, seq: ev.Seq}
+
+		// \r moves to the start of the line, so the change replaces the "-> " prompt
+		switch ev.Kind {
+		case gRPC.CounterEvent_CURRENT:
+			fmt.Printf("\r%s is %d (version %d)\n-> ", ev.Key, ev.Value, ev.Version)
+		case gRPC.CounterEvent_DELETED:
+			fmt.Printf("\r%s was deleted by %s\n-> ", ev.Key, ev.ClientName)
+		default:
+			fmt.Printf("\r%s is now %d (%+d by %s)\n-> ", ev.Key, ev.Value, ev.Delta, ev.ClientName)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package lamport
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// The interceptors below stamp every message that has an int64 field called "lamport"
+// with the time of the clock, and merge the time of every such message they receive.
+// This way no RPC handler or client call has to remember to do it,
+// and new messages only need the field to get the same treatment:
+//
+//	message Amount {
+//	    ...
+//	    int64 lamport = 3;
+//	}
+
+// stamp ticks the clock and returns a copy of msg with the time in it, if it has a lamport field, or else msg itself.
+// It doesn't change msg, as the same message may be sent to several servers at once, like a heartbeat.
+func stamp(c *Clock, msg any) any {
+	pm, ok := msg.(proto.Message)
+	if !ok {
+		return msg
+	}
+	if _, fd := lamportField(pm); fd != nil {
+		pm = proto.Clone(pm)
+		pm.ProtoReflect().Set(fd, protoreflect.ValueOfInt64(c.Tick()))
+	}
+	return pm
+}
+
+// witness merges the time in msg into the clock, if it has a lamport field.
+func witness(c *Clock, msg any) {
+	if m, fd := lamportField(msg); fd != nil {
+		c.Witness(m.Get(fd).Int())
+	}
+}
+
+func lamportField(msg any) (protoreflect.Message, protoreflect.FieldDescriptor) {
+	pm, ok := msg.(proto.Message)
+	if !ok {
+		return nil, nil
+	}
+	m := pm.ProtoReflect()
+	fd := m.Descriptor().Fields().ByName("lamport")
+	if fd == nil || fd.Kind() != protoreflect.Int64Kind || fd.Cardinality() == protoreflect.Repeated {
+		return nil, nil
+	}
+	return m, fd
+}
+
+// UnaryServerInterceptor merges the time of requests and stamps responses.
+func UnaryServerInterceptor(c *Clock) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		witness(c, req)
+		resp, err := handler(ctx, req)
+		if err == nil {
+			resp = stamp(c, resp)
+		}
+		return resp, err
+	}
+}
+
+// StreamServerInterceptor merges the time of every received message and stamps every sent one.
+func StreamServerInterceptor(c *Clock) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, &serverStream{ServerStream: ss, c: c})
+	}
+}
+
+type serverStream struct {
+	grpc.ServerStream
+	c *Clock
+}
+
+func (s *serverStream) SendMsg(m any) error {
+	return s.ServerStream.SendMsg(stamp(s.c, m))
+}
+
+func (s *serverStream) RecvMsg(m any) error {
+	err := s.ServerStream.RecvMsg(m)
+	if err == nil {
+		witness(s.c, m)
+	}
+	return err
+}
+
+// UnaryClientInterceptor stamps requests and merges the time of responses.
+func UnaryClientInterceptor(c *Clock) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		err := invoker(ctx, method, stamp(c, req), reply, cc, opts...)
+		if err == nil {
+			witness(c, reply)
+		}
+		return err
+	}
+}
+
+// StreamClientInterceptor stamps every sent message and merges the time of every received one.
+func StreamClientInterceptor(c *Clock) grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		cs, err := streamer(ctx, desc, cc, method, opts...)
+		if err != nil {
+			return nil, err
+		}
+		return &clientStream{ClientStream: cs, c: c}, nil
+	}
+}
+
+type clientStream struct {
+	grpc.ClientStream
+	c *Clock
+}
+
+func (s *clientStream) SendMsg(m any) error {
+	return s.ClientStream.SendMsg(stamp(s.c, m))
+}
+
+func (s *clientStream) RecvMsg(m any) error {
+	err := s.ClientStream.RecvMsg(m)
+	if err == nil {
+		witness(s.c, m)
+	}
+	return err
+}
@@ -0,0 +1,44 @@
+// Package lamport implements Lamport clocks, which give every event a logical time
+// so that if one event happened before another, it also has a lower time.
+//
+// The rules are simple: tick the clock before sending a message and put the time in it,
+// and when a message is received, set the clock to the highest of its own time and the
+// time in the message, plus one.
+package lamport
+
+import (
+	"sync"
+)
+
+// Clock is a Lamport clock. The zero value is a clock at time 0, ready to use.
+// It is safe for concurrent use.
+type Clock struct {
+	mu   sync.Mutex
+	time int64
+}
+
+// Now returns the current time without changing it.
+func (c *Clock) Now() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.time
+}
+
+// Tick moves the clock forward for a local event, like sending a message, and returns the new time.
+func (c *Clock) Tick() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.time++
+	return c.time
+}
+
+// Witness merges the time from a received message into the clock and returns the new time.
+func (c *Clock) Witness(t int64) int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if t > c.time {
+		c.time = t
+	}
+	c.time++
+	return c.time
+}
@@ -0,0 +1,128 @@
+// Package logging sets up structured logging with log/slog for the servers and clients.
+// Every line is a message and a set of fields, so scripts can read the logs without guessing at the format:
+//
+//	time=2024-01-01T12:00:00.000Z level=INFO msg="increment applied" node=alice port=5400 lamport=12 method=/proto.Template/Increment peer=127.0.0.1:51234 value=5
+//
+// Besides the fields given when logging, every line gets the lamport time of the process,
+// and lines logged with a context from an RPC get the method, the address of the caller and the trace id.
+package logging
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/PatrickMatthiesen/DSYS-gRPC-template/tracing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/peer"
+)
+
+// Config is how the logs are written, see RegisterFlags.
+type Config struct {
+	Level      string // debug, info, warn or error
+	Format     string // text or json
+	File       string // empty for stderr
+	MaxSize    int    // megabytes a file may grow to before it is rotated
+	MaxBackups int    // rotated files to keep
+}
+
+// RegisterFlags adds the flags for the logging to fs, and returns the config they are parsed into.
+func RegisterFlags(fs *flag.FlagSet) *Config {
+	c := &Config{}
+	fs.StringVar(&c.Level, "log-level", "info", "Lowest level to log: debug, info, warn or error")
+	fs.StringVar(&c.Format, "log-format", "text", "Format of the log lines: text (key=value) or json")
+	fs.StringVar(&c.File, "log-file", "", "File to write the log to (empty = the terminal)")
+	fs.IntVar(&c.MaxSize, "log-max-size", 10, `Megabytes "-log-file" may grow to before it is rotated`)
+	fs.IntVar(&c.MaxBackups, "log-max-backups", 3, `Rotated log files to keep, as <file>.1, <file>.2 and so on`)
+	return c
+}
+
+// Setup makes the logger described by c, with attrs on every line, and makes it the default,
+// so slog.Info and friends (and the standard log package) use it.
+// lamport is called for the lamport time of every line, it can be nil.
+// The returned closer closes the log file, if there is one.
+func Setup(c *Config, lamport func() int64, attrs ...any) (*slog.Logger, io.Closer, error) {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(c.Level)); err != nil {
+		return nil, nil, fmt.Errorf("logging: unknown level %q", c.Level)
+	}
+
+	var w io.Writer = os.Stderr
+	var closer io.Closer = io.NopCloser(nil)
+	if c.File != "" {
+		f, err := OpenRotatingFile(c.File, int64(c.MaxSize)<<20, c.MaxBackups)
+		if err != nil {
+			return nil, nil, err
+		}
+		w, closer = f, f
+	}
+
+	opts := &slog.HandlerOptions{Level: level}
+	var h slog.Handler
+	switch strings.ToLower(c.Format) {
+	case "text":
+		h = slog.NewTextHandler(w, opts)
+	case "json":
+		h = slog.NewJSONHandler(w, opts)
+	default:
+		closer.Close()
+		return nil, nil, fmt.Errorf("logging: unknown format %q", c.Format)
+	}
+
+	logger := slog.New(NewHandler(h, lamport)).With(attrs...)
+	slog.SetDefault(logger)
+	return logger, closer, nil
+}
+
+// Fatal logs msg at the error level and exits, like log.Fatal.
+func Fatal(msg string, args ...any) {
+	slog.Error(msg, args...)
+	os.Exit(1)
+}
+
+// Handler adds the lamport time, and the method, caller and trace id of an RPC, to every record before passing it on.
+type Handler struct {
+	next    slog.Handler
+	lamport func() int64
+}
+
+// NewHandler wraps next. lamport is called for the lamport time of every record, it can be nil.
+func NewHandler(next slog.Handler, lamport func() int64) *Handler {
+	return &Handler{next: next, lamport: lamport}
+}
+
+func (h *Handler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *Handler) Handle(ctx context.Context, r slog.Record) error {
+	r = r.Clone()
+	if h.lamport != nil {
+		r.AddAttrs(slog.Int64("lamport", h.lamport()))
+	}
+	if ctx != nil {
+		if method, ok := grpc.Method(ctx); ok {
+			r.AddAttrs(slog.String("method", method))
+		}
+		if p, ok := peer.FromContext(ctx); ok {
+			r.AddAttrs(slog.String("peer", p.Addr.String()))
+		}
+		if span := tracing.SpanFromContext(ctx); span != nil {
+			r.AddAttrs(slog.String("trace_id", span.TraceID()))
+		}
+	}
+	return h.next.Handle(ctx, r)
+}
+
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &Handler{next: h.next.WithAttrs(attrs), lamport: h.lamport}
+}
+
+func (h *Handler) WithGroup(name string) slog.Handler {
+	return &Handler{next: h.next.WithGroup(name), lamport: h.lamport}
+}
@@ -0,0 +1,89 @@
+package metrics
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/stats"
+	"google.golang.org/grpc/status"
+)
+
+// ServerMetrics measures every call to a gRPC server. Add the interceptors and the stats handler
+// to the server, and every method gets measured, including the ones added later.
+type ServerMetrics struct {
+	handled       *Counter
+	latency       *Histogram
+	activeStreams *Gauge
+	connections   *Gauge
+}
+
+// NewServerMetrics makes the metrics for a gRPC server in r.
+func NewServerMetrics(r *Registry) *ServerMetrics {
+	return &ServerMetrics{
+		handled:       r.NewCounter("grpc_server_handled_total", "Number of calls finished by the server, by method and status code.", "grpc_service", "grpc_method", "grpc_code"),
+		latency:       r.NewHistogram("grpc_server_handling_seconds", "How long calls took to finish, in seconds.", DefaultBuckets, "grpc_service", "grpc_method"),
+		activeStreams: r.NewGauge("grpc_server_active_streams", "Number of streams open right now.", "grpc_service", "grpc_method"),
+		connections:   r.NewGauge("grpc_server_connections", "Number of clients (and other servers) connected right now."),
+	}
+}
+
+// splitMethod splits "/proto.Template/Increment" into "proto.Template" and "Increment".
+func splitMethod(fullMethod string) (service, method string) {
+	service, method, _ = strings.Cut(strings.TrimPrefix(fullMethod, "/"), "/")
+	return service, method
+}
+
+func (m *ServerMetrics) done(fullMethod string, start time.Time, err error) {
+	service, method := splitMethod(fullMethod)
+	m.handled.With(service, method, status.Code(err).String()).Inc()
+	m.latency.With(service, method).Observe(time.Since(start).Seconds())
+}
+
+// UnaryServerInterceptor counts and times every call.
+func (m *ServerMetrics) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		m.done(info.FullMethod, start, err)
+		return resp, err
+	}
+}
+
+// StreamServerInterceptor counts and times every stream, and keeps track of how many are open.
+func (m *ServerMetrics) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		service, method := splitMethod(info.FullMethod)
+		active := m.activeStreams.With(service, method)
+		active.Inc()
+		defer active.Dec()
+
+		start := time.Now()
+		err := handler(srv, ss)
+		m.done(info.FullMethod, start, err)
+		return err
+	}
+}
+
+// StatsHandler keeps track of how many connections are open. Use it with grpc.StatsHandler.
+func (m *ServerMetrics) StatsHandler() stats.Handler {
+	return connHandler{m.connections.With()}
+}
+
+type connHandler struct {
+	connections GaugeSeries
+}
+
+func (h connHandler) TagRPC(ctx context.Context, _ *stats.RPCTagInfo) context.Context   { return ctx }
+func (h connHandler) HandleRPC(context.Context, stats.RPCStats)                         {}
+func (h connHandler) TagConn(ctx context.Context, _ *stats.ConnTagInfo) context.Context { return ctx }
+
+func (h connHandler) HandleConn(_ context.Context, s stats.ConnStats) {
+	switch s.(type) {
+	case *stats.ConnBegin:
+		h.connections.Inc()
+	case *stats.ConnEnd:
+		h.connections.Dec()
+	}
+}
@@ -0,0 +1,360 @@
+// Package metrics keeps counters, gauges and histograms, and serves them in the Prometheus text format,
+// so Prometheus (or just a browser) can see how a server is doing:
+//
+//	r := metrics.NewRegistry()
+//	calls := r.NewCounter("calls_total", "Number of calls.", "method")
+//	calls.With("Increment").Inc()
+//	http.Handle("/metrics", r.Handler())
+//
+// It only does what this project needs. For anything bigger use the real client, github.com/prometheus/client_golang.
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultBuckets are the upper bounds of the histogram buckets used for latencies in seconds, the same as Prometheus uses.
+var DefaultBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// Registry holds all the metrics of a program.
+type Registry struct {
+	mu      sync.Mutex
+	metrics []metric // in the order they were made, which is the order they are written in
+}
+
+type metric interface {
+	write(w io.Writer)
+}
+
+// NewRegistry makes an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+func (r *Registry) add(m metric) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.metrics = append(r.metrics, m)
+}
+
+// WritePrometheus writes all the metrics to w in the Prometheus text format.
+func (r *Registry) WritePrometheus(w io.Writer) {
+	r.mu.Lock()
+	metrics := append([]metric(nil), r.metrics...)
+	r.mu.Unlock()
+
+	for _, m := range metrics {
+		m.write(w)
+	}
+}
+
+// Handler serves the metrics over HTTP, for Prometheus to scrape.
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		r.WritePrometheus(w)
+	})
+}
+
+// family is what all the kinds of metrics have in common: a name, a help text, label names
+// and a value (the series) for every combination of label values that has been used.
+type family[T any] struct {
+	name   string
+	help   string
+	kind   string
+	labels []string
+
+	mu     sync.Mutex
+	series map[string]*series[T]
+	init   func() T
+}
+
+type series[T any] struct {
+	labelValues []string
+	value       T
+}
+
+func newFamily[T any](name, help, kind string, labels []string, init func() T) *family[T] {
+	return &family[T]{name: name, help: help, kind: kind, labels: labels, series: make(map[string]*series[T]), init: init}
+}
+
+// with returns the series for the label values, making it if it doesn't exist. The caller must hold f.mu.
+func (f *family[T]) with(labelValues []string) *series[T] {
+	if len(labelValues) != len(f.labels) {
+		panic(fmt.Sprintf("metrics: %s has %d labels, got %d values", f.name, len(f.labels), len(labelValues)))
+	}
+	key := strings.Join(labelValues, "\xff")
+	s, ok := f.series[key]
+	if !ok {
+		s = &series[T]{labelValues: append([]string(nil), labelValues...), value: f.init()}
+		f.series[key] = s
+	}
+	return s
+}
+
+// write writes the header and calls line for every series, sorted by their labels.
+func (f *family[T]) write(w io.Writer, line func(w io.Writer, labels string, value T)) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	fmt.Fprintf(w, "# HELP %s %s\n", f.name, f.help)
+	fmt.Fprintf(w, "# TYPE %s %s\n", f.name, f.kind)
+
+	keys := make([]string, 0, len(f.series))
+	for k := range f.series {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		s := f.series[k]
+		line(w, formatLabels(f.labels, s.labelValues), s.value)
+	}
+}
+
+// Counter is a value that only goes up, like the number of calls.
+type Counter struct {
+	f *family[float64]
+}
+
+// NewCounter makes a counter, with a series for every combination of values of the labels.
+func (r *Registry) NewCounter(name, help string, labels ...string) *Counter {
+	c := &Counter{f: newFamily(name, help, "counter", labels, func() float64 { return 0 })}
+	r.add(c)
+	return c
+}
+
+// With returns the counter for the label values, which have to be in the same order as the labels.
+func (c *Counter) With(labelValues ...string) CounterSeries {
+	return CounterSeries{c: c, labelValues: labelValues}
+}
+
+func (c *Counter) write(w io.Writer) {
+	c.f.write(w, func(w io.Writer, labels string, v float64) {
+		fmt.Fprintf(w, "%s%s %s\n", c.f.name, labels, formatFloat(v))
+	})
+}
+
+// CounterSeries is a counter with the values of its labels set.
+type CounterSeries struct {
+	c           *Counter
+	labelValues []string
+}
+
+// Inc adds one to the counter.
+func (s CounterSeries) Inc() {
+	s.Add(1)
+}
+
+// Add adds v to the counter, v must not be negative.
+func (s CounterSeries) Add(v float64) {
+	if v < 0 {
+		panic("metrics: a counter can't go down")
+	}
+	s.c.f.mu.Lock()
+	defer s.c.f.mu.Unlock()
+	s.c.f.with(s.labelValues).value += v
+}
+
+// Gauge is a value that can go up and down, like the number of open streams.
+type Gauge struct {
+	f *family[float64]
+}
+
+// NewGauge makes a gauge, with a series for every combination of values of the labels.
+func (r *Registry) NewGauge(name, help string, labels ...string) *Gauge {
+	g := &Gauge{f: newFamily(name, help, "gauge", labels, func() float64 { return 0 })}
+	r.add(g)
+	return g
+}
+
+// With returns the gauge for the label values, which have to be in the same order as the labels.
+func (g *Gauge) With(labelValues ...string) GaugeSeries {
+	return GaugeSeries{g: g, labelValues: labelValues}
+}
+
+func (g *Gauge) write(w io.Writer) {
+	g.f.write(w, func(w io.Writer, labels string, v float64) {
+		fmt.Fprintf(w, "%s%s %s\n", g.f.name, labels, formatFloat(v))
+	})
+}
+
+// GaugeSeries is a gauge with the values of its labels set.
+type GaugeSeries struct {
+	g           *Gauge
+	labelValues []string
+}
+
+// Set sets the gauge to v.
+func (s GaugeSeries) Set(v float64) {
+	s.g.f.mu.Lock()
+	defer s.g.f.mu.Unlock()
+	s.g.f.with(s.labelValues).value = v
+}
+
+// Add adds v to the gauge, which can be negative.
+func (s GaugeSeries) Add(v float64) {
+	s.g.f.mu.Lock()
+	defer s.g.f.mu.Unlock()
+	s.g.f.with(s.labelValues).value += v
+}
+
+// Inc adds one to the gauge.
+func (s GaugeSeries) Inc() { s.Add(1) }
+
+// Dec subtracts one from the gauge.
+func (s GaugeSeries) Dec() { s.Add(-1) }
+
+// gaugeFunc is a gauge without labels, whose value is read when the metrics are written.
+type gaugeFunc struct {
+	name, help string
+	value      func() float64
+}
+
+// NewGaugeFunc makes a gauge that calls value every time the metrics are written,
+// for values that are kept somewhere else, like the value of the server.
+func (r *Registry) NewGaugeFunc(name, help string, value func() float64) {
+	r.add(&gaugeFunc{name: name, help: help, value: value})
+}
+
+func (g *gaugeFunc) write(w io.Writer) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s gauge\n%s %s\n", g.name, g.help, g.name, g.name, formatFloat(g.value()))
+}
+
+// gaugeMapFunc is a gauge with one label, whose values are read when the metrics are written.
+type gaugeMapFunc struct {
+	name, help, label string
+	values            func() map[string]float64
+}
+
+// NewGaugeMapFunc makes a gauge with a series for every value of label, from the map values returns
+// every time the metrics are written. It is for values kept somewhere else that come and go, like the counters of the server.
+func (r *Registry) NewGaugeMapFunc(name, help, label string, values func() map[string]float64) {
+	r.add(&gaugeMapFunc{name: name, help: help, label: label, values: values})
+}
+
+func (g *gaugeMapFunc) write(w io.Writer) {
+	values := g.values()
+	labelValues := make([]string, 0, len(values))
+	for v := range values {
+		labelValues = append(labelValues, v)
+	}
+	sort.Strings(labelValues)
+
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s gauge\n", g.name, g.help, g.name)
+	for _, v := range labelValues {
+		fmt.Fprintf(w, "%s%s %s\n", g.name, formatLabels([]string{g.label}, []string{v}), formatFloat(values[v]))
+	}
+}
+
+// Histogram counts values in buckets, like how long calls take.
+type Histogram struct {
+	f       *family[*histogramValue]
+	buckets []float64
+}
+
+type histogramValue struct {
+	counts []uint64 // counts[i] is the number of values <= buckets[i], not including the ones in the buckets before
+	count  uint64
+	sum    float64
+}
+
+// NewHistogram makes a histogram with the given bucket upper bounds, which have to be sorted.
+// Use DefaultBuckets for latencies in seconds.
+func (r *Registry) NewHistogram(name, help string, buckets []float64, labels ...string) *Histogram {
+	h := &Histogram{buckets: buckets}
+	h.f = newFamily(name, help, "histogram", labels, func() *histogramValue {
+		return &histogramValue{counts: make([]uint64, len(buckets))}
+	})
+	r.add(h)
+	return h
+}
+
+// With returns the histogram for the label values, which have to be in the same order as the labels.
+func (h *Histogram) With(labelValues ...string) HistogramSeries {
+	return HistogramSeries{h: h, labelValues: labelValues}
+}
+
+func (h *Histogram) write(w io.Writer) {
+	h.f.write(w, func(w io.Writer, labels string, v *histogramValue) {
+		// the buckets in the text format count everything up to their bound, so the counts are added up
+		var cumulative uint64
+		for i, bound := range h.buckets {
+			cumulative += v.counts[i]
+			fmt.Fprintf(w, "%s_bucket%s %d\n", h.f.name, withLabel(labels, "le", formatFloat(bound)), cumulative)
+		}
+		fmt.Fprintf(w, "%s_bucket%s %d\n", h.f.name, withLabel(labels, "le", "+Inf"), v.count)
+		fmt.Fprintf(w, "%s_sum%s %s\n", h.f.name, labels, formatFloat(v.sum))
+		fmt.Fprintf(w, "%s_count%s %d\n", h.f.name, labels, v.count)
+	})
+}
+
+// HistogramSeries is a histogram with the values of its labels set.
+type HistogramSeries struct {
+	h           *Histogram
+	labelValues []string
+}
+
+// Observe adds v to the histogram.
+func (s HistogramSeries) Observe(v float64) {
+	s.h.f.mu.Lock()
+	defer s.h.f.mu.Unlock()
+	hv := s.h.f.with(s.labelValues).value
+	if i := sort.SearchFloat64s(s.h.buckets, v); i < len(s.h.buckets) {
+		hv.counts[i]++
+	}
+	hv.count++
+	hv.sum += v
+}
+
+// formatLabels makes {a="1",b="2"}, or nothing if there are no labels.
+func formatLabels(names, values []string) string {
+	if len(names) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteByte('{')
+	for i, name := range names {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(name)
+		b.WriteString(`="`)
+		b.WriteString(escape(values[i]))
+		b.WriteByte('"')
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+// withLabel adds one more label to labels made by formatLabels.
+func withLabel(labels, name, value string) string {
+	l := name + `="` + escape(value) + `"`
+	if labels == "" {
+		return "{" + l + "}"
+	}
+	return labels[:len(labels)-1] + "," + l + "}"
+}
+
+var escaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escape(s string) string {
+	return escaper.Replace(s)
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	default:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	}
+}
@@ -19,8 +19,17 @@ import (
 const _ = grpc.SupportPackageIsVersion7
 
 const (
-	Template_Increment_FullMethodName = "/proto.Template/Increment"
-	Template_SayHi_FullMethodName     = "/proto.Template/SayHi"
+	Template_Increment_FullMethodName     = "/proto.Template/Increment"
+	Template_SayHi_FullMethodName         = "/proto.Template/SayHi"
+	Template_Chat_FullMethodName          = "/proto.Template/Chat"
+	Template_Get_FullMethodName           = "/proto.Template/Get"
+	Template_List_FullMethodName          = "/proto.Template/List"
+	Template_Delete_FullMethodName        = "/proto.Template/Delete"
+	Template_Reset_FullMethodName         = "/proto.Template/Reset"
+	Template_CompareAndSet_FullMethodName = "/proto.Template/CompareAndSet"
+	Template_IncrementIf_FullMethodName   = "/proto.Template/IncrementIf"
+	Template_Watch_FullMethodName         = "/proto.Template/Watch"
+	Template_Snapshot_FullMethodName      = "/proto.Template/Snapshot"
 )
 
 // TemplateClient is the client API for Template service.
@@ -31,6 +40,28 @@ type TemplateClient interface {
 	Increment(ctx context.Context, in *Amount, opts ...grpc.CallOption) (*Ack, error)
 	// many messages are sent and one is recieved
 	SayHi(ctx context.Context, opts ...grpc.CallOption) (Template_SayHiClient, error)
+	// many messages are sent and recieved at the same time
+	Chat(ctx context.Context, opts ...grpc.CallOption) (Template_ChatClient, error)
+	// the value of a single counter, NOT_FOUND if nobody has incremented it yet
+	Get(ctx context.Context, in *Key, opts ...grpc.CallOption) (*Counter, error)
+	// every counter and its value, ordered by key
+	List(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (*CounterList, error)
+	// removes a counter and returns the value it had, the next increment starts it over from 0
+	Delete(ctx context.Context, in *Key, opts ...grpc.CallOption) (*Counter, error)
+	// sets a counter back to 0 and returns the value it had
+	Reset(ctx context.Context, in *Key, opts ...grpc.CallOption) (*Counter, error)
+	// sets a counter to new if its value is expected, and returns it.
+	// Otherwise it fails with FAILED_PRECONDITION, with the current Counter in the status details.
+	CompareAndSet(ctx context.Context, in *CasRequest, opts ...grpc.CallOption) (*Counter, error)
+	// increments a counter if its version is still the one the client read, and returns it.
+	// Otherwise it fails with FAILED_PRECONDITION, with the current Counter in the status details.
+	IncrementIf(ctx context.Context, in *ConditionalAmount, opts ...grpc.CallOption) (*Counter, error)
+	// one message is sent and many are recieved:
+	// every change to the counters with keys starting with prefix, as they happen.
+	// A client that reconnects sends the seq of the last event it got, and gets the ones it missed first.
+	Watch(ctx context.Context, in *WatchRequest, opts ...grpc.CallOption) (Template_WatchClient, error)
+	// takes a snapshot of the counters on every server, and the transfers between them, at one point in time
+	Snapshot(ctx context.Context, in *SnapshotRequest, opts ...grpc.CallOption) (*SnapshotResult, error)
 }
 
 type templateClient struct {
@@ -84,6 +115,132 @@ func (x *templateSayHiClient) CloseAndRecv() (*Farewell, error) {
 	return m, nil
 }
 
+func (c *templateClient) Chat(ctx context.Context, opts ...grpc.CallOption) (Template_ChatClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Template_ServiceDesc.Streams[1], Template_Chat_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &templateChatClient{stream}
+	return x, nil
+}
+
+type Template_ChatClient interface {
+	Send(*ChatMessage) error
+	Recv() (*ChatMessage, error)
+	grpc.ClientStream
+}
+
+type templateChatClient struct {
+	grpc.ClientStream
+}
+
+func (x *templateChatClient) Send(m *ChatMessage) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *templateChatClient) Recv() (*ChatMessage, error) {
+	m := new(ChatMessage)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *templateClient) Get(ctx context.Context, in *Key, opts ...grpc.CallOption) (*Counter, error) {
+	out := new(Counter)
+	err := c.cc.Invoke(ctx, Template_Get_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *templateClient) List(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (*CounterList, error) {
+	out := new(CounterList)
+	err := c.cc.Invoke(ctx, Template_List_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *templateClient) Delete(ctx context.Context, in *Key, opts ...grpc.CallOption) (*Counter, error) {
+	out := new(Counter)
+	err := c.cc.Invoke(ctx, Template_Delete_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *templateClient) Reset(ctx context.Context, in *Key, opts ...grpc.CallOption) (*Counter, error) {
+	out := new(Counter)
+	err := c.cc.Invoke(ctx, Template_Reset_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *templateClient) CompareAndSet(ctx context.Context, in *CasRequest, opts ...grpc.CallOption) (*Counter, error) {
+	out := new(Counter)
+	err := c.cc.Invoke(ctx, Template_CompareAndSet_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *templateClient) IncrementIf(ctx context.Context, in *ConditionalAmount, opts ...grpc.CallOption) (*Counter, error) {
+	out := new(Counter)
+	err := c.cc.Invoke(ctx, Template_IncrementIf_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *templateClient) Watch(ctx context.Context, in *WatchRequest, opts ...grpc.CallOption) (Template_WatchClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Template_ServiceDesc.Streams[2], Template_Watch_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &templateWatchClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Template_WatchClient interface {
+	Recv() (*CounterEvent, error)
+	grpc.ClientStream
+}
+
+type templateWatchClient struct {
+	grpc.ClientStream
+}
+
+func (x *templateWatchClient) Recv() (*CounterEvent, error) {
+	m := new(CounterEvent)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *templateClient) Snapshot(ctx context.Context, in *SnapshotRequest, opts ...grpc.CallOption) (*SnapshotResult, error) {
+	out := new(SnapshotResult)
+	err := c.cc.Invoke(ctx, Template_Snapshot_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // TemplateServer is the server API for Template service.
 // All implementations must embed UnimplementedTemplateServer
 // for forward compatibility
@@ -92,6 +249,28 @@ type TemplateServer interface {
 	Increment(context.Context, *Amount) (*Ack, error)
 	// many messages are sent and one is recieved
 	SayHi(Template_SayHiServer) error
+	// many messages are sent and recieved at the same time
+	Chat(Template_ChatServer) error
+	// the value of a single counter, NOT_FOUND if nobody has incremented it yet
+	Get(context.Context, *Key) (*Counter, error)
+	// every counter and its value, ordered by key
+	List(context.Context, *ListRequest) (*CounterList, error)
+	// removes a counter and returns the value it had, the next increment starts it over from 0
+	Delete(context.Context, *Key) (*Counter, error)
+	// sets a counter back to 0 and returns the value it had
+	Reset(context.Context, *Key) (*Counter, error)
+	// sets a counter to new if its value is expected, and returns it.
+	// Otherwise it fails with FAILED_PRECONDITION, with the current Counter in the status details.
+	CompareAndSet(context.Context, *CasRequest) (*Counter, error)
+	// increments a counter if its version is still the one the client read, and returns it.
+	// Otherwise it fails with FAILED_PRECONDITION, with the current Counter in the status details.
+	IncrementIf(context.Context, *ConditionalAmount) (*Counter, error)
+	// one message is sent and many are recieved:
+	// every change to the counters with keys starting with prefix, as they happen.
+	// A client that reconnects sends the seq of the last event it got, and gets the ones it missed first.
+	Watch(*WatchRequest, Template_WatchServer) error
+	// takes a snapshot of the counters on every server, and the transfers between them, at one point in time
+	Snapshot(context.Context, *SnapshotRequest) (*SnapshotResult, error)
 	mustEmbedUnimplementedTemplateServer()
 }
 
@@ -105,6 +284,33 @@ func (UnimplementedTemplateServer) Increment(context.Context, *Amount) (*Ack, er
 func (UnimplementedTemplateServer) SayHi(Template_SayHiServer) error {
 	return status.Errorf(codes.Unimplemented, "method SayHi not implemented")
 }
+func (UnimplementedTemplateServer) Chat(Template_ChatServer) error {
+	return status.Errorf(codes.Unimplemented, "method Chat not implemented")
+}
+func (UnimplementedTemplateServer) Get(context.Context, *Key) (*Counter, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Get not implemented")
+}
+func (UnimplementedTemplateServer) List(context.Context, *ListRequest) (*CounterList, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method List not implemented")
+}
+func (UnimplementedTemplateServer) Delete(context.Context, *Key) (*Counter, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Delete not implemented")
+}
+func (UnimplementedTemplateServer) Reset(context.Context, *Key) (*Counter, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Reset not implemented")
+}
+func (UnimplementedTemplateServer) CompareAndSet(context.Context, *CasRequest) (*Counter, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CompareAndSet not implemented")
+}
+func (UnimplementedTemplateServer) IncrementIf(context.Context, *ConditionalAmount) (*Counter, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method IncrementIf not implemented")
+}
+func (UnimplementedTemplateServer) Watch(*WatchRequest, Template_WatchServer) error {
+	return status.Errorf(codes.Unimplemented, "method Watch not implemented")
+}
+func (UnimplementedTemplateServer) Snapshot(context.Context, *SnapshotRequest) (*SnapshotResult, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Snapshot not implemented")
+}
 func (UnimplementedTemplateServer) mustEmbedUnimplementedTemplateServer() {}
 
 // UnsafeTemplateServer may be embedded to opt out of forward compatibility for this service.
@@ -162,6 +368,179 @@ func (x *templateSayHiServer) Recv() (*Greeding, error) {
 	return m, nil
 }
 
+func _Template_Chat_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(TemplateServer).Chat(&templateChatServer{stream})
+}
+
+type Template_ChatServer interface {
+	Send(*ChatMessage) error
+	Recv() (*ChatMessage, error)
+	grpc.ServerStream
+}
+
+type templateChatServer struct {
+	grpc.ServerStream
+}
+
+func (x *templateChatServer) Send(m *ChatMessage) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *templateChatServer) Recv() (*ChatMessage, error) {
+	m := new(ChatMessage)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _Template_Get_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Key)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TemplateServer).Get(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Template_Get_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TemplateServer).Get(ctx, req.(*Key))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Template_List_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TemplateServer).List(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Template_List_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TemplateServer).List(ctx, req.(*ListRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Template_Delete_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Key)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TemplateServer).Delete(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Template_Delete_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TemplateServer).Delete(ctx, req.(*Key))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Template_Reset_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Key)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TemplateServer).Reset(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Template_Reset_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TemplateServer).Reset(ctx, req.(*Key))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Template_CompareAndSet_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CasRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TemplateServer).CompareAndSet(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Template_CompareAndSet_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TemplateServer).CompareAndSet(ctx, req.(*CasRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Template_IncrementIf_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ConditionalAmount)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TemplateServer).IncrementIf(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Template_IncrementIf_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TemplateServer).IncrementIf(ctx, req.(*ConditionalAmount))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Template_Watch_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(TemplateServer).Watch(m, &templateWatchServer{stream})
+}
+
+type Template_WatchServer interface {
+	Send(*CounterEvent) error
+	grpc.ServerStream
+}
+
+type templateWatchServer struct {
+	grpc.ServerStream
+}
+
+func (x *templateWatchServer) Send(m *CounterEvent) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Template_Snapshot_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SnapshotRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TemplateServer).Snapshot(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Template_Snapshot_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TemplateServer).Snapshot(ctx, req.(*SnapshotRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // Template_ServiceDesc is the grpc.ServiceDesc for Template service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -173,6 +552,34 @@ var Template_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "Increment",
 			Handler:    _Template_Increment_Handler,
 		},
+		{
+			MethodName: "Get",
+			Handler:    _Template_Get_Handler,
+		},
+		{
+			MethodName: "List",
+			Handler:    _Template_List_Handler,
+		},
+		{
+			MethodName: "Delete",
+			Handler:    _Template_Delete_Handler,
+		},
+		{
+			MethodName: "Reset",
+			Handler:    _Template_Reset_Handler,
+		},
+		{
+			MethodName: "CompareAndSet",
+			Handler:    _Template_CompareAndSet_Handler,
+		},
+		{
+			MethodName: "IncrementIf",
+			Handler:    _Template_IncrementIf_Handler,
+		},
+		{
+			MethodName: "Snapshot",
+			Handler:    _Template_Snapshot_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
@@ -180,6 +587,1012 @@ var Template_ServiceDesc = grpc.ServiceDesc{
 			Handler:       _Template_SayHi_Handler,
 			ClientStreams: true,
 		},
+		{
+			StreamName:    "Chat",
+			Handler:       _Template_Chat_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "Watch",
+			Handler:       _Template_Watch_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "proto/template.proto",
+}
+
+const (
+	Replication_Replicate_FullMethodName = "/proto.Replication/Replicate"
+	Replication_Heartbeat_FullMethodName = "/proto.Replication/Heartbeat"
+)
+
+// ReplicationClient is the client API for Replication service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type ReplicationClient interface {
+	// the primary sends every update to the backups before it answers the client
+	Replicate(ctx context.Context, in *Update, opts ...grpc.CallOption) (*UpdateAck, error)
+	// the primary lets the backups know it is still alive
+	Heartbeat(ctx context.Context, in *Beat, opts ...grpc.CallOption) (*BeatAck, error)
+}
+
+type replicationClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewReplicationClient(cc grpc.ClientConnInterface) ReplicationClient {
+	return &replicationClient{cc}
+}
+
+func (c *replicationClient) Replicate(ctx context.Context, in *Update, opts ...grpc.CallOption) (*UpdateAck, error) {
+	out := new(UpdateAck)
+	err := c.cc.Invoke(ctx, Replication_Replicate_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *replicationClient) Heartbeat(ctx context.Context, in *Beat, opts ...grpc.CallOption) (*BeatAck, error) {
+	out := new(BeatAck)
+	err := c.cc.Invoke(ctx, Replication_Heartbeat_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ReplicationServer is the server API for Replication service.
+// All implementations must embed UnimplementedReplicationServer
+// for forward compatibility
+type ReplicationServer interface {
+	// the primary sends every update to the backups before it answers the client
+	Replicate(context.Context, *Update) (*UpdateAck, error)
+	// the primary lets the backups know it is still alive
+	Heartbeat(context.Context, *Beat) (*BeatAck, error)
+	mustEmbedUnimplementedReplicationServer()
+}
+
+// UnimplementedReplicationServer must be embedded to have forward compatible implementations.
+type UnimplementedReplicationServer struct {
+}
+
+func (UnimplementedReplicationServer) Replicate(context.Context, *Update) (*UpdateAck, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Replicate not implemented")
+}
+func (UnimplementedReplicationServer) Heartbeat(context.Context, *Beat) (*BeatAck, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Heartbeat not implemented")
+}
+func (UnimplementedReplicationServer) mustEmbedUnimplementedReplicationServer() {}
+
+// UnsafeReplicationServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ReplicationServer will
+// result in compilation errors.
+type UnsafeReplicationServer interface {
+	mustEmbedUnimplementedReplicationServer()
+}
+
+func RegisterReplicationServer(s grpc.ServiceRegistrar, srv ReplicationServer) {
+	s.RegisterService(&Replication_ServiceDesc, srv)
+}
+
+func _Replication_Replicate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Update)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReplicationServer).Replicate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Replication_Replicate_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReplicationServer).Replicate(ctx, req.(*Update))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Replication_Heartbeat_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Beat)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReplicationServer).Heartbeat(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Replication_Heartbeat_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReplicationServer).Heartbeat(ctx, req.(*Beat))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Replication_ServiceDesc is the grpc.ServiceDesc for Replication service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Replication_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "proto.Replication",
+	HandlerType: (*ReplicationServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Replicate",
+			Handler:    _Replication_Replicate_Handler,
+		},
+		{
+			MethodName: "Heartbeat",
+			Handler:    _Replication_Heartbeat_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "proto/template.proto",
+}
+
+const (
+	Raft_RequestVote_FullMethodName   = "/proto.Raft/RequestVote"
+	Raft_AppendEntries_FullMethodName = "/proto.Raft/AppendEntries"
+)
+
+// RaftClient is the client API for Raft service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type RaftClient interface {
+	// a candidate asks for a vote in an election
+	RequestVote(ctx context.Context, in *VoteRequest, opts ...grpc.CallOption) (*VoteReply, error)
+	// the leader sends new entries to a follower, or an empty one as a heartbeat
+	AppendEntries(ctx context.Context, in *AppendRequest, opts ...grpc.CallOption) (*AppendReply, error)
+}
+
+type raftClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewRaftClient(cc grpc.ClientConnInterface) RaftClient {
+	return &raftClient{cc}
+}
+
+func (c *raftClient) RequestVote(ctx context.Context, in *VoteRequest, opts ...grpc.CallOption) (*VoteReply, error) {
+	out := new(VoteReply)
+	err := c.cc.Invoke(ctx, Raft_RequestVote_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *raftClient) AppendEntries(ctx context.Context, in *AppendRequest, opts ...grpc.CallOption) (*AppendReply, error) {
+	out := new(AppendReply)
+	err := c.cc.Invoke(ctx, Raft_AppendEntries_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// RaftServer is the server API for Raft service.
+// All implementations must embed UnimplementedRaftServer
+// for forward compatibility
+type RaftServer interface {
+	// a candidate asks for a vote in an election
+	RequestVote(context.Context, *VoteRequest) (*VoteReply, error)
+	// the leader sends new entries to a follower, or an empty one as a heartbeat
+	AppendEntries(context.Context, *AppendRequest) (*AppendReply, error)
+	mustEmbedUnimplementedRaftServer()
+}
+
+// UnimplementedRaftServer must be embedded to have forward compatible implementations.
+type UnimplementedRaftServer struct {
+}
+
+func (UnimplementedRaftServer) RequestVote(context.Context, *VoteRequest) (*VoteReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RequestVote not implemented")
+}
+func (UnimplementedRaftServer) AppendEntries(context.Context, *AppendRequest) (*AppendReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AppendEntries not implemented")
+}
+func (UnimplementedRaftServer) mustEmbedUnimplementedRaftServer() {}
+
+// UnsafeRaftServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to RaftServer will
+// result in compilation errors.
+type UnsafeRaftServer interface {
+	mustEmbedUnimplementedRaftServer()
+}
+
+func RegisterRaftServer(s grpc.ServiceRegistrar, srv RaftServer) {
+	s.RegisterService(&Raft_ServiceDesc, srv)
+}
+
+func _Raft_RequestVote_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(VoteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RaftServer).RequestVote(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Raft_RequestVote_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RaftServer).RequestVote(ctx, req.(*VoteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Raft_AppendEntries_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AppendRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RaftServer).AppendEntries(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Raft_AppendEntries_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RaftServer).AppendEntries(ctx, req.(*AppendRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Raft_ServiceDesc is the grpc.ServiceDesc for Raft service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Raft_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "proto.Raft",
+	HandlerType: (*RaftServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "RequestVote",
+			Handler:    _Raft_RequestVote_Handler,
+		},
+		{
+			MethodName: "AppendEntries",
+			Handler:    _Raft_AppendEntries_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "proto/template.proto",
+}
+
+const (
+	MutualExclusion_Request_FullMethodName = "/proto.MutualExclusion/Request"
+	MutualExclusion_Reply_FullMethodName   = "/proto.MutualExclusion/Reply"
+)
+
+// MutualExclusionClient is the client API for MutualExclusion service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type MutualExclusionClient interface {
+	// a node asks the others for the critical section
+	Request(ctx context.Context, in *MutexRequest, opts ...grpc.CallOption) (*MutexAck, error)
+	// a node gives its permission, right away or when it leaves the critical section itself
+	Reply(ctx context.Context, in *MutexReply, opts ...grpc.CallOption) (*MutexAck, error)
+}
+
+type mutualExclusionClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewMutualExclusionClient(cc grpc.ClientConnInterface) MutualExclusionClient {
+	return &mutualExclusionClient{cc}
+}
+
+func (c *mutualExclusionClient) Request(ctx context.Context, in *MutexRequest, opts ...grpc.CallOption) (*MutexAck, error) {
+	out := new(MutexAck)
+	err := c.cc.Invoke(ctx, MutualExclusion_Request_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *mutualExclusionClient) Reply(ctx context.Context, in *MutexReply, opts ...grpc.CallOption) (*MutexAck, error) {
+	out := new(MutexAck)
+	err := c.cc.Invoke(ctx, MutualExclusion_Reply_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// MutualExclusionServer is the server API for MutualExclusion service.
+// All implementations must embed UnimplementedMutualExclusionServer
+// for forward compatibility
+type MutualExclusionServer interface {
+	// a node asks the others for the critical section
+	Request(context.Context, *MutexRequest) (*MutexAck, error)
+	// a node gives its permission, right away or when it leaves the critical section itself
+	Reply(context.Context, *MutexReply) (*MutexAck, error)
+	mustEmbedUnimplementedMutualExclusionServer()
+}
+
+// UnimplementedMutualExclusionServer must be embedded to have forward compatible implementations.
+type UnimplementedMutualExclusionServer struct {
+}
+
+func (UnimplementedMutualExclusionServer) Request(context.Context, *MutexRequest) (*MutexAck, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Request not implemented")
+}
+func (UnimplementedMutualExclusionServer) Reply(context.Context, *MutexReply) (*MutexAck, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Reply not implemented")
+}
+func (UnimplementedMutualExclusionServer) mustEmbedUnimplementedMutualExclusionServer() {}
+
+// UnsafeMutualExclusionServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to MutualExclusionServer will
+// result in compilation errors.
+type UnsafeMutualExclusionServer interface {
+	mustEmbedUnimplementedMutualExclusionServer()
+}
+
+func RegisterMutualExclusionServer(s grpc.ServiceRegistrar, srv MutualExclusionServer) {
+	s.RegisterService(&MutualExclusion_ServiceDesc, srv)
+}
+
+func _MutualExclusion_Request_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MutexRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MutualExclusionServer).Request(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MutualExclusion_Request_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MutualExclusionServer).Request(ctx, req.(*MutexRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MutualExclusion_Reply_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MutexReply)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MutualExclusionServer).Reply(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MutualExclusion_Reply_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MutualExclusionServer).Reply(ctx, req.(*MutexReply))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// MutualExclusion_ServiceDesc is the grpc.ServiceDesc for MutualExclusion service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var MutualExclusion_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "proto.MutualExclusion",
+	HandlerType: (*MutualExclusionServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Request",
+			Handler:    _MutualExclusion_Request_Handler,
+		},
+		{
+			MethodName: "Reply",
+			Handler:    _MutualExclusion_Reply_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "proto/template.proto",
+}
+
+const (
+	TokenRing_PassToken_FullMethodName = "/proto.TokenRing/PassToken"
+)
+
+// TokenRingClient is the client API for TokenRing service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type TokenRingClient interface {
+	// a node passes the token on to the next node in the ring
+	PassToken(ctx context.Context, in *Token, opts ...grpc.CallOption) (*TokenAck, error)
+}
+
+type tokenRingClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewTokenRingClient(cc grpc.ClientConnInterface) TokenRingClient {
+	return &tokenRingClient{cc}
+}
+
+func (c *tokenRingClient) PassToken(ctx context.Context, in *Token, opts ...grpc.CallOption) (*TokenAck, error) {
+	out := new(TokenAck)
+	err := c.cc.Invoke(ctx, TokenRing_PassToken_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// TokenRingServer is the server API for TokenRing service.
+// All implementations must embed UnimplementedTokenRingServer
+// for forward compatibility
+type TokenRingServer interface {
+	// a node passes the token on to the next node in the ring
+	PassToken(context.Context, *Token) (*TokenAck, error)
+	mustEmbedUnimplementedTokenRingServer()
+}
+
+// UnimplementedTokenRingServer must be embedded to have forward compatible implementations.
+type UnimplementedTokenRingServer struct {
+}
+
+func (UnimplementedTokenRingServer) PassToken(context.Context, *Token) (*TokenAck, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PassToken not implemented")
+}
+func (UnimplementedTokenRingServer) mustEmbedUnimplementedTokenRingServer() {}
+
+// UnsafeTokenRingServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to TokenRingServer will
+// result in compilation errors.
+type UnsafeTokenRingServer interface {
+	mustEmbedUnimplementedTokenRingServer()
+}
+
+func RegisterTokenRingServer(s grpc.ServiceRegistrar, srv TokenRingServer) {
+	s.RegisterService(&TokenRing_ServiceDesc, srv)
+}
+
+func _TokenRing_PassToken_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Token)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TokenRingServer).PassToken(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TokenRing_PassToken_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TokenRingServer).PassToken(ctx, req.(*Token))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// TokenRing_ServiceDesc is the grpc.ServiceDesc for TokenRing service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var TokenRing_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "proto.TokenRing",
+	HandlerType: (*TokenRingServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "PassToken",
+			Handler:    _TokenRing_PassToken_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "proto/template.proto",
+}
+
+const (
+	Bully_Election_FullMethodName    = "/proto.Bully/Election"
+	Bully_Answer_FullMethodName      = "/proto.Bully/Answer"
+	Bully_Coordinator_FullMethodName = "/proto.Bully/Coordinator"
+	Bully_Heartbeat_FullMethodName   = "/proto.Bully/Heartbeat"
+)
+
+// BullyClient is the client API for Bully service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type BullyClient interface {
+	// a node asks the nodes with higher ids if any of them are alive, to take over the election
+	Election(ctx context.Context, in *BullyMessage, opts ...grpc.CallOption) (*BullyAck, error)
+	// a node with a higher id tells the one that started the election that it takes over
+	Answer(ctx context.Context, in *BullyMessage, opts ...grpc.CallOption) (*BullyAck, error)
+	// the new leader tells everyone that it won
+	Coordinator(ctx context.Context, in *BullyMessage, opts ...grpc.CallOption) (*BullyAck, error)
+	// the leader tells everyone that it is still alive
+	Heartbeat(ctx context.Context, in *BullyMessage, opts ...grpc.CallOption) (*BullyAck, error)
+}
+
+type bullyClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewBullyClient(cc grpc.ClientConnInterface) BullyClient {
+	return &bullyClient{cc}
+}
+
+func (c *bullyClient) Election(ctx context.Context, in *BullyMessage, opts ...grpc.CallOption) (*BullyAck, error) {
+	out := new(BullyAck)
+	err := c.cc.Invoke(ctx, Bully_Election_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *bullyClient) Answer(ctx context.Context, in *BullyMessage, opts ...grpc.CallOption) (*BullyAck, error) {
+	out := new(BullyAck)
+	err := c.cc.Invoke(ctx, Bully_Answer_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *bullyClient) Coordinator(ctx context.Context, in *BullyMessage, opts ...grpc.CallOption) (*BullyAck, error) {
+	out := new(BullyAck)
+	err := c.cc.Invoke(ctx, Bully_Coordinator_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *bullyClient) Heartbeat(ctx context.Context, in *BullyMessage, opts ...grpc.CallOption) (*BullyAck, error) {
+	out := new(BullyAck)
+	err := c.cc.Invoke(ctx, Bully_Heartbeat_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// BullyServer is the server API for Bully service.
+// All implementations must embed UnimplementedBullyServer
+// for forward compatibility
+type BullyServer interface {
+	// a node asks the nodes with higher ids if any of them are alive, to take over the election
+	Election(context.Context, *BullyMessage) (*BullyAck, error)
+	// a node with a higher id tells the one that started the election that it takes over
+	Answer(context.Context, *BullyMessage) (*BullyAck, error)
+	// the new leader tells everyone that it won
+	Coordinator(context.Context, *BullyMessage) (*BullyAck, error)
+	// the leader tells everyone that it is still alive
+	Heartbeat(context.Context, *BullyMessage) (*BullyAck, error)
+	mustEmbedUnimplementedBullyServer()
+}
+
+// UnimplementedBullyServer must be embedded to have forward compatible implementations.
+type UnimplementedBullyServer struct {
+}
+
+func (UnimplementedBullyServer) Election(context.Context, *BullyMessage) (*BullyAck, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Election not implemented")
+}
+func (UnimplementedBullyServer) Answer(context.Context, *BullyMessage) (*BullyAck, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Answer not implemented")
+}
+func (UnimplementedBullyServer) Coordinator(context.Context, *BullyMessage) (*BullyAck, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Coordinator not implemented")
+}
+func (UnimplementedBullyServer) Heartbeat(context.Context, *BullyMessage) (*BullyAck, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Heartbeat not implemented")
+}
+func (UnimplementedBullyServer) mustEmbedUnimplementedBullyServer() {}
+
+// UnsafeBullyServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to BullyServer will
+// result in compilation errors.
+type UnsafeBullyServer interface {
+	mustEmbedUnimplementedBullyServer()
+}
+
+func RegisterBullyServer(s grpc.ServiceRegistrar, srv BullyServer) {
+	s.RegisterService(&Bully_ServiceDesc, srv)
+}
+
+func _Bully_Election_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BullyMessage)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BullyServer).Election(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Bully_Election_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BullyServer).Election(ctx, req.(*BullyMessage))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Bully_Answer_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BullyMessage)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BullyServer).Answer(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Bully_Answer_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BullyServer).Answer(ctx, req.(*BullyMessage))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Bully_Coordinator_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BullyMessage)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BullyServer).Coordinator(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Bully_Coordinator_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BullyServer).Coordinator(ctx, req.(*BullyMessage))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Bully_Heartbeat_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BullyMessage)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BullyServer).Heartbeat(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Bully_Heartbeat_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BullyServer).Heartbeat(ctx, req.(*BullyMessage))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Bully_ServiceDesc is the grpc.ServiceDesc for Bully service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Bully_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "proto.Bully",
+	HandlerType: (*BullyServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Election",
+			Handler:    _Bully_Election_Handler,
+		},
+		{
+			MethodName: "Answer",
+			Handler:    _Bully_Answer_Handler,
+		},
+		{
+			MethodName: "Coordinator",
+			Handler:    _Bully_Coordinator_Handler,
+		},
+		{
+			MethodName: "Heartbeat",
+			Handler:    _Bully_Heartbeat_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "proto/template.proto",
+}
+
+const (
+	RingElection_Election_FullMethodName  = "/proto.RingElection/Election"
+	RingElection_Elected_FullMethodName   = "/proto.RingElection/Elected"
+	RingElection_Heartbeat_FullMethodName = "/proto.RingElection/Heartbeat"
+)
+
+// RingElectionClient is the client API for RingElection service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type RingElectionClient interface {
+	// a candidate goes around the ring, every node passes on the highest id it has seen
+	Election(ctx context.Context, in *RingElectionMessage, opts ...grpc.CallOption) (*RingElectionAck, error)
+	// the winner goes around the ring, so everyone knows who the leader is
+	Elected(ctx context.Context, in *RingElectionMessage, opts ...grpc.CallOption) (*RingElectionAck, error)
+	// the leader tells everyone that it is still alive
+	Heartbeat(ctx context.Context, in *RingElectionMessage, opts ...grpc.CallOption) (*RingElectionAck, error)
+}
+
+type ringElectionClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewRingElectionClient(cc grpc.ClientConnInterface) RingElectionClient {
+	return &ringElectionClient{cc}
+}
+
+func (c *ringElectionClient) Election(ctx context.Context, in *RingElectionMessage, opts ...grpc.CallOption) (*RingElectionAck, error) {
+	out := new(RingElectionAck)
+	err := c.cc.Invoke(ctx, RingElection_Election_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ringElectionClient) Elected(ctx context.Context, in *RingElectionMessage, opts ...grpc.CallOption) (*RingElectionAck, error) {
+	out := new(RingElectionAck)
+	err := c.cc.Invoke(ctx, RingElection_Elected_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ringElectionClient) Heartbeat(ctx context.Context, in *RingElectionMessage, opts ...grpc.CallOption) (*RingElectionAck, error) {
+	out := new(RingElectionAck)
+	err := c.cc.Invoke(ctx, RingElection_Heartbeat_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// RingElectionServer is the server API for RingElection service.
+// All implementations must embed UnimplementedRingElectionServer
+// for forward compatibility
+type RingElectionServer interface {
+	// a candidate goes around the ring, every node passes on the highest id it has seen
+	Election(context.Context, *RingElectionMessage) (*RingElectionAck, error)
+	// the winner goes around the ring, so everyone knows who the leader is
+	Elected(context.Context, *RingElectionMessage) (*RingElectionAck, error)
+	// the leader tells everyone that it is still alive
+	Heartbeat(context.Context, *RingElectionMessage) (*RingElectionAck, error)
+	mustEmbedUnimplementedRingElectionServer()
+}
+
+// UnimplementedRingElectionServer must be embedded to have forward compatible implementations.
+type UnimplementedRingElectionServer struct {
+}
+
+func (UnimplementedRingElectionServer) Election(context.Context, *RingElectionMessage) (*RingElectionAck, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Election not implemented")
+}
+func (UnimplementedRingElectionServer) Elected(context.Context, *RingElectionMessage) (*RingElectionAck, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Elected not implemented")
+}
+func (UnimplementedRingElectionServer) Heartbeat(context.Context, *RingElectionMessage) (*RingElectionAck, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Heartbeat not implemented")
+}
+func (UnimplementedRingElectionServer) mustEmbedUnimplementedRingElectionServer() {}
+
+// UnsafeRingElectionServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to RingElectionServer will
+// result in compilation errors.
+type UnsafeRingElectionServer interface {
+	mustEmbedUnimplementedRingElectionServer()
+}
+
+func RegisterRingElectionServer(s grpc.ServiceRegistrar, srv RingElectionServer) {
+	s.RegisterService(&RingElection_ServiceDesc, srv)
+}
+
+func _RingElection_Election_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RingElectionMessage)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RingElectionServer).Election(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RingElection_Election_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RingElectionServer).Election(ctx, req.(*RingElectionMessage))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RingElection_Elected_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RingElectionMessage)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RingElectionServer).Elected(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RingElection_Elected_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RingElectionServer).Elected(ctx, req.(*RingElectionMessage))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RingElection_Heartbeat_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RingElectionMessage)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RingElectionServer).Heartbeat(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RingElection_Heartbeat_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RingElectionServer).Heartbeat(ctx, req.(*RingElectionMessage))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// RingElection_ServiceDesc is the grpc.ServiceDesc for RingElection service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var RingElection_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "proto.RingElection",
+	HandlerType: (*RingElectionServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Election",
+			Handler:    _RingElection_Election_Handler,
+		},
+		{
+			MethodName: "Elected",
+			Handler:    _RingElection_Elected_Handler,
+		},
+		{
+			MethodName: "Heartbeat",
+			Handler:    _RingElection_Heartbeat_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "proto/template.proto",
+}
+
+const (
+	GlobalSnapshot_Deliver_FullMethodName = "/proto.GlobalSnapshot/Deliver"
+	GlobalSnapshot_Report_FullMethodName  = "/proto.GlobalSnapshot/Report"
+)
+
+// GlobalSnapshotClient is the client API for GlobalSnapshot service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type GlobalSnapshotClient interface {
+	// a message on the channel from one server to another. They are sent one at a time, so they arrive in order
+	Deliver(ctx context.Context, in *ChannelMessage, opts ...grpc.CallOption) (*ChannelAck, error)
+	// a server sends its part of a snapshot to the one that started it
+	Report(ctx context.Context, in *LocalSnapshot, opts ...grpc.CallOption) (*ChannelAck, error)
+}
+
+type globalSnapshotClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewGlobalSnapshotClient(cc grpc.ClientConnInterface) GlobalSnapshotClient {
+	return &globalSnapshotClient{cc}
+}
+
+func (c *globalSnapshotClient) Deliver(ctx context.Context, in *ChannelMessage, opts ...grpc.CallOption) (*ChannelAck, error) {
+	out := new(ChannelAck)
+	err := c.cc.Invoke(ctx, GlobalSnapshot_Deliver_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *globalSnapshotClient) Report(ctx context.Context, in *LocalSnapshot, opts ...grpc.CallOption) (*ChannelAck, error) {
+	out := new(ChannelAck)
+	err := c.cc.Invoke(ctx, GlobalSnapshot_Report_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GlobalSnapshotServer is the server API for GlobalSnapshot service.
+// All implementations must embed UnimplementedGlobalSnapshotServer
+// for forward compatibility
+type GlobalSnapshotServer interface {
+	// a message on the channel from one server to another. They are sent one at a time, so they arrive in order
+	Deliver(context.Context, *ChannelMessage) (*ChannelAck, error)
+	// a server sends its part of a snapshot to the one that started it
+	Report(context.Context, *LocalSnapshot) (*ChannelAck, error)
+	mustEmbedUnimplementedGlobalSnapshotServer()
+}
+
+// UnimplementedGlobalSnapshotServer must be embedded to have forward compatible implementations.
+type UnimplementedGlobalSnapshotServer struct {
+}
+
+func (UnimplementedGlobalSnapshotServer) Deliver(context.Context, *ChannelMessage) (*ChannelAck, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Deliver not implemented")
+}
+func (UnimplementedGlobalSnapshotServer) Report(context.Context, *LocalSnapshot) (*ChannelAck, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Report not implemented")
+}
+func (UnimplementedGlobalSnapshotServer) mustEmbedUnimplementedGlobalSnapshotServer() {}
+
+// UnsafeGlobalSnapshotServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to GlobalSnapshotServer will
+// result in compilation errors.
+type UnsafeGlobalSnapshotServer interface {
+	mustEmbedUnimplementedGlobalSnapshotServer()
+}
+
+func RegisterGlobalSnapshotServer(s grpc.ServiceRegistrar, srv GlobalSnapshotServer) {
+	s.RegisterService(&GlobalSnapshot_ServiceDesc, srv)
+}
+
+func _GlobalSnapshot_Deliver_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ChannelMessage)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GlobalSnapshotServer).Deliver(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GlobalSnapshot_Deliver_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GlobalSnapshotServer).Deliver(ctx, req.(*ChannelMessage))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GlobalSnapshot_Report_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LocalSnapshot)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GlobalSnapshotServer).Report(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GlobalSnapshot_Report_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GlobalSnapshotServer).Report(ctx, req.(*LocalSnapshot))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// GlobalSnapshot_ServiceDesc is the grpc.ServiceDesc for GlobalSnapshot service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var GlobalSnapshot_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "proto.GlobalSnapshot",
+	HandlerType: (*GlobalSnapshotServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Deliver",
+			Handler:    _GlobalSnapshot_Deliver_Handler,
+		},
+		{
+			MethodName: "Report",
+			Handler:    _GlobalSnapshot_Report_Handler,
+		},
 	},
+	Streams:  []grpc.StreamDesc{},
 	Metadata: "proto/template.proto",
 }
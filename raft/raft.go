@@ -0,0 +1,553 @@
+// Package raft implements the Raft consensus algorithm, so a group of servers
+// can agree on a log of commands and apply them in the same order.
+//
+// The paper the implementation follows is "In Search of an Understandable
+// Consensus Algorithm" by Ongaro and Ousterhout: <https://raft.github.io/raft.pdf>.
+// Figure 2 in it sums up the whole algorithm on one page, and the comments
+// below use the same names.
+//
+// A command passed to Propose on the leader is appended to its log and sent
+// to the followers. Once a majority has it in their log it is committed, and
+// every node applies it to its state machine by calling Config.Apply.
+// Log compaction (snapshots of the state machine) is not implemented, so the
+// log is replayed from the start when a node restarts.
+package raft
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"math/rand"
+	"os"
+	"sync"
+	"time"
+
+	gRPC "github.com/PatrickMatthiesen/DSYS-gRPC-template/proto"
+
+	"google.golang.org/grpc"
+)
+
+// ErrNotLeader is returned by Propose when the node isn't the leader.
+// Leader returns the address of the node that is, if it is known.
+var ErrNotLeader = errors.New("raft: not the leader")
+
+// ErrLost is returned by Propose when the node lost its leadership before
+// the command was committed, and the command was replaced by another one.
+var ErrLost = errors.New("raft: command was lost in a leader change")
+
+// the most entries sent in a single AppendEntries
+const maxBatch = 100
+
+type Config struct {
+	Name  string   // used in the log, if Logger is nil
+	Addr  string   // the address the other nodes can reach this node at, it is also the id of the node
+	Peers []string // the addresses of the other nodes
+	Dir   string   // folder to keep the term, vote and log in ("" = memory only)
+
+	HeartbeatInterval time.Duration
+	ElectionTimeout   time.Duration // the actual timeout is random, between this and twice this
+
+	DialOptions []grpc.DialOption // used when dialing the peers
+
+	// Apply is called with every committed command, in log order and from a single goroutine.
+	// What it returns is handed back to the caller of Propose.
+	Apply func(command []byte) any
+
+	Logger *slog.Logger // where the node logs to (nil = slog.Default(), with the name of the node)
+}
+
+type role int
+
+const (
+	follower role = iota
+	candidate
+	leader
+)
+
+type Node struct {
+	cfg     Config
+	logger  *slog.Logger
+	storage *storage
+
+	mu sync.Mutex
+
+	// persistent state on all servers
+	currentTerm int64
+	votedFor    string
+	log         []entry // log[0] is an empty entry, so indexes match the paper
+
+	// volatile state on all servers
+	commitIndex uint64
+	lastApplied uint64
+	role        role
+	leader      string    // the address of the current leader, if known
+	deadline    time.Time // when to start an election if we haven't heard from a leader
+
+	// volatile state on leaders, reset after each election
+	nextIndex  map[string]uint64
+	matchIndex map[string]uint64
+	notify     map[string]chan struct{} // wakes up the goroutine sending entries to a peer
+
+	waiters map[uint64]waiter // Propose calls waiting for their entry to be applied
+	applyCh chan struct{}     // wakes up the goroutine applying committed entries
+
+	clientsMu sync.Mutex // clients is used without holding mu
+	clients   map[string]gRPC.RaftClient
+
+	stop    chan struct{}
+	stopped bool // set by Stop, after which nothing may change the persistent state
+}
+
+// waiter is a Propose call waiting for the entry at some index to be applied.
+type waiter struct {
+	term   int64
+	result chan any // gets the result of Apply, or ErrLost
+}
+
+// New creates a node, loading its term, vote and log from cfg.Dir.
+func New(cfg Config) (*Node, error) {
+	n := &Node{
+		cfg:     cfg,
+		logger:  newLogger(cfg.Logger, cfg.Name),
+		log:     []entry{{}},
+		waiters: make(map[uint64]waiter),
+		applyCh: make(chan struct{}, 1),
+		clients: make(map[string]gRPC.RaftClient),
+		stop:    make(chan struct{}),
+	}
+
+	if cfg.Dir != "" {
+		s, st, err := openStorage(cfg.Dir)
+		if err != nil {
+			return nil, err
+		}
+		n.storage = s
+		n.currentTerm, n.votedFor, n.log = st.Term, st.Vote, st.Log
+		n.logger.Info("loaded state", "term", n.currentTerm, "entries", len(n.log)-1, "dir", cfg.Dir)
+	}
+
+	n.resetDeadline()
+	return n, nil
+}
+
+// Start starts the timers and the goroutine that applies committed entries.
+func (n *Node) Start() {
+	go n.ticker()
+	go n.applier()
+}
+
+// Stop stops the node and closes its storage. It can't be started again.
+func (n *Node) Stop() {
+	close(n.stop)
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.stopped = true
+	if err := n.storage.close(); err != nil {
+		n.logger.Error("failed to close storage", "err", err)
+	}
+}
+
+// Leader returns the address of the current leader, or "" if it isn't known.
+func (n *Node) Leader() string {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.leader
+}
+
+// IsLeader reports whether the node thinks it is the leader.
+func (n *Node) IsLeader() bool {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.role == leader
+}
+
+// Propose appends command to the log and waits for it to be committed and applied.
+// It returns what Config.Apply returned for the command.
+func (n *Node) Propose(ctx context.Context, command []byte) (any, error) {
+	n.mu.Lock()
+	if n.role != leader || n.stopped {
+		n.mu.Unlock()
+		return nil, ErrNotLeader
+	}
+
+	e := entry{Term: n.currentTerm, Command: command}
+	index := uint64(len(n.log))
+	if err := n.storage.saveEntries(index, []entry{e}); err != nil {
+		n.mu.Unlock()
+		return nil, err
+	}
+	n.log = append(n.log, e)
+
+	w := waiter{term: e.Term, result: make(chan any, 1)}
+	n.waiters[index] = w
+	n.advanceCommit() // without peers we are the majority, and it is committed right away
+	n.replicate()
+	n.mu.Unlock()
+
+	select {
+	case res := <-w.result:
+		if err, ok := res.(error); ok && err == ErrLost {
+			return nil, err
+		}
+		return res, nil
+	case <-ctx.Done():
+		n.mu.Lock()
+		delete(n.waiters, index)
+		n.mu.Unlock()
+		return nil, ctx.Err()
+	}
+}
+
+// resetDeadline picks a new random election timeout. The caller must hold n.mu.
+func (n *Node) resetDeadline() {
+	timeout := n.cfg.ElectionTimeout + time.Duration(rand.Int63n(int64(n.cfg.ElectionTimeout)))
+	n.deadline = time.Now().Add(timeout)
+}
+
+// becomeFollower is called when we see a term that is newer than ours. The caller must hold n.mu.
+func (n *Node) becomeFollower(term int64) {
+	if n.role == leader {
+		n.logger.Info("saw a newer term, stepping down as leader", "term", term)
+		// closing the channels makes the senders see that we aren't the leader anymore, and exit
+		for _, ch := range n.notify {
+			close(ch)
+		}
+		n.notify = nil
+	}
+	n.role = follower
+	if term > n.currentTerm {
+		n.currentTerm = term
+		n.votedFor = ""
+		n.leader = ""
+		n.persistVote()
+	}
+}
+
+// persistVote saves the current term and vote. The caller must hold n.mu.
+func (n *Node) persistVote() {
+	if err := n.storage.saveVote(n.currentTerm, n.votedFor); err != nil {
+		// raft isn't safe if it forgets a vote, so it is better to stop
+		n.fatal("failed to persist term and vote", "err", err)
+	}
+}
+
+func (n *Node) lastLog() (uint64, int64) {
+	last := uint64(len(n.log) - 1)
+	return last, n.log[last].Term
+}
+
+// ticker starts elections and sends heartbeats.
+func (n *Node) ticker() {
+	heartbeat := time.NewTicker(n.cfg.HeartbeatInterval)
+	defer heartbeat.Stop()
+	check := time.NewTicker(10 * time.Millisecond)
+	defer check.Stop()
+
+	for {
+		select {
+		case <-n.stop:
+			return
+		case <-heartbeat.C:
+			n.mu.Lock()
+			if n.role == leader {
+				n.replicate()
+			}
+			n.mu.Unlock()
+		case <-check.C:
+			n.mu.Lock()
+			if n.role != leader && !n.stopped && time.Now().After(n.deadline) {
+				n.startElection()
+			}
+			n.mu.Unlock()
+		}
+	}
+}
+
+// startElection votes for ourselves and asks the peers for their votes. The caller must hold n.mu.
+func (n *Node) startElection() {
+	n.role = candidate
+	n.currentTerm++
+	n.votedFor = n.cfg.Addr
+	n.leader = ""
+	n.persistVote()
+	n.resetDeadline()
+
+	term := n.currentTerm
+	lastIndex, lastTerm := n.lastLog()
+	req := &gRPC.VoteRequest{Term: term, Candidate: n.cfg.Addr, LastLogIndex: lastIndex, LastLogTerm: lastTerm}
+	n.logger.Info("starting election", "term", term)
+
+	votes := 1 // our own
+	if votes > len(n.cfg.Peers)/2 {
+		n.becomeLeader() // there are no peers
+		return
+	}
+
+	for _, peer := range n.cfg.Peers {
+		go func(peer string) {
+			ctx, cancel := context.WithTimeout(context.Background(), n.cfg.ElectionTimeout)
+			defer cancel()
+			reply, err := n.client(peer).RequestVote(ctx, req)
+			if err != nil {
+				return
+			}
+
+			n.mu.Lock()
+			defer n.mu.Unlock()
+			if reply.Term > n.currentTerm {
+				n.becomeFollower(reply.Term)
+				return
+			}
+			if n.role != candidate || n.currentTerm != term || !reply.VoteGranted {
+				return
+			}
+			votes++
+			if votes > len(n.cfg.Peers)/2 {
+				n.becomeLeader()
+			}
+		}(peer)
+	}
+}
+
+// becomeLeader is called when a candidate has the votes of a majority. The caller must hold n.mu.
+func (n *Node) becomeLeader() {
+	n.logger.Info("won the election", "term", n.currentTerm)
+	n.role = leader
+	n.leader = n.cfg.Addr
+
+	// entries from earlier terms can't be committed by counting replicas,
+	// so we add an empty entry from our own term which commits them along with it
+	e := entry{Term: n.currentTerm}
+	index := uint64(len(n.log))
+	if err := n.storage.saveEntries(index, []entry{e}); err != nil {
+		n.fatal("failed to persist log", "err", err)
+	}
+	n.log = append(n.log, e)
+
+	n.nextIndex = make(map[string]uint64)
+	n.matchIndex = make(map[string]uint64)
+	n.notify = make(map[string]chan struct{})
+	for _, peer := range n.cfg.Peers {
+		n.nextIndex[peer] = index
+		n.matchIndex[peer] = 0
+		n.notify[peer] = make(chan struct{}, 1)
+		go n.sender(peer, n.currentTerm, n.notify[peer])
+	}
+	n.advanceCommit()
+	n.replicate()
+}
+
+// replicate wakes up the goroutines that send entries to the peers. The caller must hold n.mu.
+func (n *Node) replicate() {
+	for _, ch := range n.notify {
+		select {
+		case ch <- struct{}{}:
+		default: // it already has a wake up waiting
+		}
+	}
+}
+
+// sender sends entries (or heartbeats) to peer every time it is woken up,
+// for as long as we are the leader of term.
+func (n *Node) sender(peer string, term int64, wake chan struct{}) {
+	for {
+		select {
+		case <-n.stop:
+			return
+		case <-wake:
+		}
+
+		// keep sending until the peer has everything, or something went wrong
+		for {
+			more, ok := n.sendEntries(peer, term)
+			if !ok {
+				return // no longer the leader of term
+			}
+			if !more {
+				break
+			}
+		}
+	}
+}
+
+// sendEntries sends a single AppendEntries to peer. It returns whether there is more
+// to send, and false for ok if the node is no longer the leader of term.
+func (n *Node) sendEntries(peer string, term int64) (more bool, ok bool) {
+	n.mu.Lock()
+	if n.role != leader || n.currentTerm != term {
+		n.mu.Unlock()
+		return false, false
+	}
+	next := n.nextIndex[peer]
+	prev := next - 1
+	end := uint64(len(n.log))
+	if end-next > maxBatch {
+		end = next + maxBatch
+	}
+	entries := make([]*gRPC.LogEntry, 0, end-next)
+	for _, e := range n.log[next:end] {
+		entries = append(entries, &gRPC.LogEntry{Term: e.Term, Command: e.Command})
+	}
+	req := &gRPC.AppendRequest{
+		Term:         term,
+		Leader:       n.cfg.Addr,
+		PrevLogIndex: prev,
+		PrevLogTerm:  n.log[prev].Term,
+		Entries:      entries,
+		LeaderCommit: n.commitIndex,
+	}
+	n.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), n.cfg.HeartbeatInterval)
+	defer cancel()
+	reply, err := n.client(peer).AppendEntries(ctx, req)
+	if err != nil {
+		return false, true // the peer is down, we retry on the next heartbeat
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if reply.Term > n.currentTerm {
+		n.becomeFollower(reply.Term)
+		return false, false
+	}
+	if n.role != leader || n.currentTerm != term {
+		return false, false
+	}
+
+	if reply.Success {
+		match := prev + uint64(len(entries))
+		if match > n.matchIndex[peer] {
+			n.matchIndex[peer] = match
+			n.nextIndex[peer] = match + 1
+			n.advanceCommit()
+		}
+		return n.nextIndex[peer] < uint64(len(n.log)), true
+	}
+
+	// the logs don't match at prev, so we go back to where they might
+	next = reply.ConflictIndex
+	if reply.ConflictTerm > 0 {
+		for i := prev; i > 0; i-- {
+			if n.log[i].Term == reply.ConflictTerm {
+				next = i + 1
+				break
+			}
+		}
+	}
+	if next < 1 {
+		next = 1
+	}
+	n.nextIndex[peer] = next
+	return true, true
+}
+
+// advanceCommit commits the newest entry from our term that a majority has. The caller must hold n.mu.
+func (n *Node) advanceCommit() {
+	for index := uint64(len(n.log) - 1); index > n.commitIndex; index-- {
+		if n.log[index].Term != n.currentTerm {
+			break // older entries are committed together with one from our term
+		}
+		count := 1 // ourselves
+		for _, match := range n.matchIndex {
+			if match >= index {
+				count++
+			}
+		}
+		if count > (len(n.cfg.Peers)+1)/2 {
+			n.setCommit(index)
+			return
+		}
+	}
+}
+
+// setCommit moves the commit index forward and wakes up the applier. The caller must hold n.mu.
+func (n *Node) setCommit(index uint64) {
+	if index <= n.commitIndex {
+		return
+	}
+	n.commitIndex = index
+	select {
+	case n.applyCh <- struct{}{}:
+	default:
+	}
+}
+
+// applier applies committed entries in order and answers the Propose calls waiting for them.
+func (n *Node) applier() {
+	for {
+		select {
+		case <-n.stop:
+			return
+		case <-n.applyCh:
+		}
+
+		for {
+			n.mu.Lock()
+			if n.lastApplied >= n.commitIndex {
+				n.mu.Unlock()
+				break
+			}
+			n.lastApplied++
+			index := n.lastApplied
+			e := n.log[index]
+			w, waiting := n.waiters[index]
+			delete(n.waiters, index)
+			n.mu.Unlock()
+
+			var res any
+			if len(e.Command) > 0 {
+				res = n.cfg.Apply(e.Command)
+			}
+			if waiting {
+				if w.term != e.Term {
+					// a new leader replaced our entry with its own
+					res = ErrLost
+				}
+				w.result <- res
+			}
+		}
+
+		n.mu.Lock()
+		if err := n.storage.compact(persistent{Term: n.currentTerm, Vote: n.votedFor, Log: n.log}); err != nil {
+			n.logger.Warn("failed to compact storage", "err", err)
+		}
+		n.mu.Unlock()
+	}
+}
+
+// client returns a client for peer, dialing it the first time.
+func (n *Node) client(peer string) gRPC.RaftClient {
+	n.clientsMu.Lock()
+	defer n.clientsMu.Unlock()
+
+	if c, ok := n.clients[peer]; ok {
+		return c
+	}
+	// without grpc.WithBlock this doesn't wait for the connection,
+	// so it only fails if the options are wrong
+	conn, err := grpc.Dial(peer, n.cfg.DialOptions...)
+	if err != nil {
+		n.fatal("failed to dial peer", "peer", peer, "err", err)
+	}
+	c := gRPC.NewRaftClient(conn)
+	n.clients[peer] = c
+	return c
+}
+
+// newLogger returns logger, or the default logger with the name of the node if it is nil,
+// and marks everything it logs as coming from raft.
+func newLogger(logger *slog.Logger, name string) *slog.Logger {
+	if logger == nil {
+		logger = slog.Default().With("node", name)
+	}
+	return logger.With("component", "raft")
+}
+
+// fatal logs msg and stops the program. Used when the node can't keep its promises,
+// like when it can't write its vote to disk.
+func (n *Node) fatal(msg string, args ...any) {
+	n.logger.Error(msg, args...)
+	os.Exit(1)
+}
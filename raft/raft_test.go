@@ -0,0 +1,172 @@
+package raft
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// cluster is a group of nodes that talk to each other over in-memory connections.
+type cluster struct {
+	nodes     []*Node
+	listeners map[string]*bufconn.Listener
+
+	mu      sync.Mutex
+	applied map[string][]string // the commands each node has applied, in order
+}
+
+func newCluster(t *testing.T, size int) *cluster {
+	t.Helper()
+	c := &cluster{listeners: make(map[string]*bufconn.Listener), applied: make(map[string][]string)}
+
+	addrs := make([]string, size)
+	for i := range addrs {
+		addrs[i] = fmt.Sprintf("node%d", i)
+		c.listeners[addrs[i]] = bufconn.Listen(1 << 20)
+	}
+	dial := grpc.WithContextDialer(func(ctx context.Context, addr string) (net.Conn, error) {
+		l, ok := c.listeners[addr]
+		if !ok {
+			return nil, fmt.Errorf("no node at %s", addr)
+		}
+		return l.DialContext(ctx)
+	})
+
+	for i, addr := range addrs {
+		var peers []string
+		for j, peer := range addrs {
+			if j != i {
+				peers = append(peers, peer)
+			}
+		}
+		addr := addr
+		n, err := New(Config{
+			Name:              addr,
+			Addr:              addr,
+			Peers:             peers,
+			Dir:               t.TempDir(),
+			HeartbeatInterval: 20 * time.Millisecond,
+			ElectionTimeout:   100 * time.Millisecond,
+			DialOptions:       []grpc.DialOption{dial, grpc.WithTransportCredentials(insecure.NewCredentials())},
+			Apply: func(command []byte) any {
+				c.mu.Lock()
+				defer c.mu.Unlock()
+				c.applied[addr] = append(c.applied[addr], string(command))
+				return len(c.applied[addr])
+			},
+		})
+		if err != nil {
+			t.Fatalf("New: %v", err)
+		}
+		s := grpc.NewServer()
+		n.Register(s)
+		go s.Serve(c.listeners[addr])
+		t.Cleanup(s.Stop)
+		t.Cleanup(n.Stop)
+		n.Start()
+		c.nodes = append(c.nodes, n)
+	}
+	return c
+}
+
+// leader waits for a node to become the leader and returns it.
+func (c *cluster) leader(t *testing.T) *Node {
+	t.Helper()
+	for start := time.Now(); time.Since(start) < 5*time.Second; time.Sleep(10 * time.Millisecond) {
+		for _, n := range c.nodes {
+			if n.IsLeader() {
+				return n
+			}
+		}
+	}
+	t.Fatal("no leader was elected")
+	return nil
+}
+
+// waitApplied waits until every node has applied want, in that order.
+func (c *cluster) waitApplied(t *testing.T, want []string) {
+	t.Helper()
+	for start := time.Now(); time.Since(start) < 5*time.Second; time.Sleep(10 * time.Millisecond) {
+		if c.allApplied(want) {
+			return
+		}
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	t.Fatalf("applied = %q, want %q on every node", c.applied, want)
+}
+
+func (c *cluster) allApplied(want []string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, n := range c.nodes {
+		got := c.applied[n.cfg.Addr]
+		if len(got) != len(want) {
+			return false
+		}
+		for i := range want {
+			if got[i] != want[i] {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func propose(t *testing.T, n *Node, command string) any {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	res, err := n.Propose(ctx, []byte(command))
+	if err != nil {
+		t.Fatalf("Propose(%q): %v", command, err)
+	}
+	return res
+}
+
+func TestSingleNodeCommit(t *testing.T) {
+	c := newCluster(t, 1)
+	leader := c.leader(t)
+
+	for i := 1; i <= 3; i++ {
+		if res := propose(t, leader, "cmd"+strconv.Itoa(i)); res != i {
+			t.Errorf("Propose returned %v, want %d", res, i)
+		}
+	}
+	c.waitApplied(t, []string{"cmd1", "cmd2", "cmd3"})
+}
+
+func TestThreeNodeCommit(t *testing.T) {
+	c := newCluster(t, 3)
+	leader := c.leader(t)
+
+	want := []string{"a", "b", "c"}
+	for i, command := range want {
+		if res := propose(t, leader, command); res != i+1 {
+			t.Errorf("Propose(%q) returned %v, want %d", command, res, i+1)
+		}
+	}
+	c.waitApplied(t, want)
+}
+
+func TestProposeOnFollower(t *testing.T) {
+	c := newCluster(t, 3)
+	leader := c.leader(t)
+
+	for _, n := range c.nodes {
+		if n == leader {
+			continue
+		}
+		if _, err := n.Propose(context.Background(), []byte("x")); err != ErrNotLeader {
+			t.Errorf("Propose on a follower = %v, want %v", err, ErrNotLeader)
+		}
+	}
+}
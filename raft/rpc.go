@@ -0,0 +1,115 @@
+package raft
+
+import (
+	"context"
+
+	gRPC "github.com/PatrickMatthiesen/DSYS-gRPC-template/proto"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// server handles the Raft RPCs for a node.
+type server struct {
+	gRPC.UnimplementedRaftServer
+	n *Node
+}
+
+// Register registers the node's Raft service on s.
+func (n *Node) Register(s *grpc.Server) {
+	gRPC.RegisterRaftServer(s, &server{n: n})
+}
+
+var errStopped = status.Error(codes.Unavailable, "raft: node is stopped")
+
+// RequestVote grants the vote if we haven't voted for someone else in the term,
+// and the candidate's log is at least as up to date as ours.
+func (s *server) RequestVote(ctx context.Context, req *gRPC.VoteRequest) (*gRPC.VoteReply, error) {
+	n := s.n
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if n.stopped {
+		return nil, errStopped
+	}
+	if req.Term > n.currentTerm {
+		n.becomeFollower(req.Term)
+	}
+	if req.Term < n.currentTerm || (n.votedFor != "" && n.votedFor != req.Candidate) {
+		return &gRPC.VoteReply{Term: n.currentTerm, VoteGranted: false}, nil
+	}
+
+	lastIndex, lastTerm := n.lastLog()
+	upToDate := req.LastLogTerm > lastTerm || (req.LastLogTerm == lastTerm && req.LastLogIndex >= lastIndex)
+	if !upToDate {
+		return &gRPC.VoteReply{Term: n.currentTerm, VoteGranted: false}, nil
+	}
+
+	n.votedFor = req.Candidate
+	n.persistVote()
+	n.resetDeadline()
+	return &gRPC.VoteReply{Term: n.currentTerm, VoteGranted: true}, nil
+}
+
+// AppendEntries adds the leader's entries to our log, replacing any that conflict with them.
+func (s *server) AppendEntries(ctx context.Context, req *gRPC.AppendRequest) (*gRPC.AppendReply, error) {
+	n := s.n
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if n.stopped {
+		return nil, errStopped
+	}
+	if req.Term < n.currentTerm {
+		return &gRPC.AppendReply{Term: n.currentTerm, Success: false}, nil
+	}
+
+	// there is a leader for the term, and it is not us
+	n.becomeFollower(req.Term)
+	n.leader = req.Leader
+	n.resetDeadline()
+
+	// our log has to contain the entry before the new ones
+	lastIndex, _ := n.lastLog()
+	if req.PrevLogIndex > lastIndex {
+		return &gRPC.AppendReply{Term: n.currentTerm, Success: false, ConflictIndex: lastIndex + 1}, nil
+	}
+	if term := n.log[req.PrevLogIndex].Term; term != req.PrevLogTerm {
+		// tell the leader where our entries from the conflicting term start
+		first := req.PrevLogIndex
+		for first > 1 && n.log[first-1].Term == term {
+			first--
+		}
+		return &gRPC.AppendReply{Term: n.currentTerm, Success: false, ConflictIndex: first, ConflictTerm: term}, nil
+	}
+
+	// skip the entries we already have, and cut off our log where it starts to differ.
+	// entries after the ones we got are only removed if they conflict,
+	// as this request could be older than one we have already handled.
+	for i, e := range req.Entries {
+		index := req.PrevLogIndex + 1 + uint64(i)
+		if index < uint64(len(n.log)) && n.log[index].Term == e.Term {
+			continue
+		}
+
+		entries := make([]entry, 0, len(req.Entries)-i)
+		for _, e := range req.Entries[i:] {
+			entries = append(entries, entry{Term: e.Term, Command: e.Command})
+		}
+		if err := n.storage.saveEntries(index, entries); err != nil {
+			return nil, status.Errorf(codes.Internal, "raft: failed to persist log: %v", err)
+		}
+		n.log = append(n.log[:index], entries...)
+		break
+	}
+
+	if req.LeaderCommit > n.commitIndex {
+		last := req.PrevLogIndex + uint64(len(req.Entries))
+		if req.LeaderCommit < last {
+			last = req.LeaderCommit
+		}
+		n.setCommit(last)
+	}
+	return &gRPC.AppendReply{Term: n.currentTerm, Success: true}, nil
+}
@@ -0,0 +1,122 @@
+package raft
+
+import (
+	"encoding/json"
+
+	"github.com/PatrickMatthiesen/DSYS-gRPC-template/wal"
+)
+
+// how many records are written to the write-ahead log before it is compacted into a snapshot
+const compactEvery = 1000
+
+// storage keeps the term, vote and log on disk using a write-ahead log.
+// Every change is a record, and a record with an entry replaces everything
+// in the log from its index and on, which is how conflicting entries are removed.
+type storage struct {
+	wal     *wal.Log
+	records int // records written since the last snapshot
+}
+
+// record is a single change to the persistent state.
+// Either Term and Vote are set, or Index and Entry are.
+type record struct {
+	Term  int64  `json:"term,omitempty"`
+	Vote  string `json:"vote,omitempty"`
+	Index uint64 `json:"index,omitempty"`
+	Entry *entry `json:"entry,omitempty"`
+}
+
+type entry struct {
+	Term    int64  `json:"term"`
+	Command []byte `json:"command,omitempty"`
+}
+
+// persistent is everything raft has to remember across restarts, and what goes in a snapshot.
+type persistent struct {
+	Term int64   `json:"term"`
+	Vote string  `json:"vote"`
+	Log  []entry `json:"log"` // log[0] is an empty entry, so the first real entry has index 1
+}
+
+// openStorage opens the write-ahead log in dir and replays it.
+func openStorage(dir string) (*storage, persistent, error) {
+	st := persistent{Log: []entry{{}}}
+
+	l, snapshot, records, err := wal.Open(dir)
+	if err != nil {
+		return nil, st, err
+	}
+	if snapshot != nil {
+		if err := json.Unmarshal(snapshot, &st); err != nil {
+			l.Close()
+			return nil, st, err
+		}
+	}
+
+	for _, data := range records {
+		var rec record
+		if err := json.Unmarshal(data, &rec); err != nil {
+			l.Close()
+			return nil, st, err
+		}
+		if rec.Entry != nil {
+			st.Log = append(st.Log[:rec.Index], *rec.Entry)
+		} else {
+			st.Term, st.Vote = rec.Term, rec.Vote
+		}
+	}
+	return &storage{wal: l, records: len(records)}, st, nil
+}
+
+// saveVote persists the current term and vote.
+func (s *storage) saveVote(term int64, vote string) error {
+	return s.append(record{Term: term, Vote: vote})
+}
+
+// saveEntries persists entries, which start at index and replace anything after it.
+func (s *storage) saveEntries(index uint64, entries []entry) error {
+	for i := range entries {
+		if err := s.append(record{Index: index + uint64(i), Entry: &entries[i]}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *storage) append(rec record) error {
+	if s == nil {
+		return nil // running without a data folder
+	}
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	if err := s.wal.Append(data); err != nil {
+		return err
+	}
+	s.records++
+	return nil
+}
+
+// compact replaces the records with a snapshot of st, when there are enough of them.
+func (s *storage) compact(st persistent) error {
+	if s == nil || s.records < compactEvery {
+		return nil
+	}
+	data, err := json.Marshal(st)
+	if err != nil {
+		return err
+	}
+	if err := s.wal.Snapshot(data); err != nil {
+		return err
+	}
+	s.records = 0
+	return nil
+}
+
+func (s *storage) close() error {
+	if s == nil {
+		return nil
+	}
+	return s.wal.Close()
+}
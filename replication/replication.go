@@ -0,0 +1,418 @@
+// Package replication implements primary-backup replication between servers.
+//
+// One node is the primary. Every write goes through Replicate on the primary,
+// which sends it to all up to date backups and waits for them to apply it
+// before the write is acknowledged. The primary heartbeats the backups with
+// the list of up to date backups (the view), in the order they take over.
+// When the heartbeats stop, the first backup in the view promotes itself;
+// the second one waits twice as long, and so on.
+//
+// A backup that misses an update, or a new node, is dropped from the view
+// and brought back by sending it the full state.
+//
+// Each promotion bumps the epoch, so an old primary that comes back is told
+// about the new one and steps down.
+package replication
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+
+	gRPC "github.com/PatrickMatthiesen/DSYS-gRPC-template/proto"
+
+	"google.golang.org/grpc"
+)
+
+// ErrNotPrimary is returned by Replicate when the node is a backup.
+var ErrNotPrimary = errors.New("replication: not the primary")
+
+type Config struct {
+	Name    string   // used in the log, if Logger is nil
+	Addr    string   // the address the other nodes can reach this node at
+	Peers   []string // the other nodes, in the order they should take over as primary
+	Primary bool     // start out as the primary
+
+	HeartbeatInterval time.Duration
+	FailoverTimeout   time.Duration // how long without a heartbeat before the first backup takes over
+
+	DialOptions []grpc.DialOption // used when dialing the peers
+
+	// Locker protects the state the functions below work on.
+	// It must be held when calling Replicate, and the node holds it when it calls them.
+	Locker   sync.Locker
+	Apply    func(entry []byte) error // applies an entry on a backup
+	Snapshot func() ([]byte, error)   // returns the full state, to bring a backup up to date
+	Restore  func(state []byte) error // replaces the full state on a backup
+
+	Logger *slog.Logger // where the node logs to (nil = slog.Default(), with the name of the node)
+}
+
+type Node struct {
+	cfg    Config
+	logger *slog.Logger
+
+	mu          sync.Mutex
+	primary     bool
+	primaryAddr string
+	epoch       int64
+	seq         uint64          // the last entry this node has
+	view        []string        // up to date backups, in the order they take over
+	inSync      map[string]bool // (primary only) backups that have every entry
+	lastBeat    time.Time       // (backup only) when we last heard from the primary
+
+	clientsMu sync.Mutex // clients is used without holding mu
+	clients   map[string]gRPC.ReplicationClient
+
+	stop chan struct{}
+}
+
+func New(cfg Config) *Node {
+	n := &Node{
+		cfg:      cfg,
+		logger:   newLogger(cfg.Logger, cfg.Name),
+		primary:  cfg.Primary,
+		inSync:   make(map[string]bool),
+		lastBeat: time.Now(),
+		clients:  make(map[string]gRPC.ReplicationClient),
+		stop:     make(chan struct{}),
+	}
+	if cfg.Primary {
+		n.primaryAddr = cfg.Addr
+	}
+	return n
+}
+
+// Start starts heartbeating (as primary) or watching the primary (as backup).
+func (n *Node) Start() {
+	go n.loop()
+}
+
+// Stop stops the node. It can't be started again.
+func (n *Node) Stop() {
+	close(n.stop)
+}
+
+// IsPrimary reports whether the node is currently the primary.
+func (n *Node) IsPrimary() bool {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.primary
+}
+
+// Primary returns the address of the current primary, or "" if it isn't known yet.
+func (n *Node) Primary() string {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.primaryAddr
+}
+
+// Replicate sends entry to every up to date backup and returns when they have applied it.
+// Backups that don't answer are dropped from the view, so a crashed backup doesn't block writes.
+// The caller must hold cfg.Locker, and should only apply entry itself if Replicate succeeds.
+func (n *Node) Replicate(ctx context.Context, entry []byte) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if !n.primary {
+		return ErrNotPrimary
+	}
+
+	n.seq++
+	update := &gRPC.Update{Epoch: n.epoch, Primary: n.cfg.Addr, Seq: n.seq, Entry: entry}
+
+	ctx, cancel := context.WithTimeout(ctx, n.cfg.FailoverTimeout)
+	defer cancel()
+
+	acks := make([]*gRPC.UpdateAck, len(n.view))
+	errs := make([]error, len(n.view))
+	var wg sync.WaitGroup
+	for i, peer := range n.view {
+		wg.Add(1)
+		go func(i int, peer string) {
+			defer wg.Done()
+			acks[i], errs[i] = n.client(peer).Replicate(ctx, update)
+		}(i, peer)
+	}
+	wg.Wait()
+
+	for i, peer := range n.view {
+		if errs[i] == nil && acks[i].Ok {
+			continue
+		}
+		if errs[i] == nil && acks[i].Epoch > n.epoch {
+			n.stepDown(acks[i].Epoch)
+			return ErrNotPrimary
+		}
+		n.logger.Warn("backup missed an update, dropping it from the view", "backup", peer, "seq", n.seq)
+		n.inSync[peer] = false
+	}
+	n.updateView()
+	return nil
+}
+
+// server handles the Replication RPCs for a node.
+type server struct {
+	gRPC.UnimplementedReplicationServer
+	n *Node
+}
+
+// Register registers the node's Replication service on s.
+func (n *Node) Register(s *grpc.Server) {
+	gRPC.RegisterReplicationServer(s, &server{n: n})
+}
+
+// Replicate is the backup side of Node.Replicate.
+func (s *server) Replicate(ctx context.Context, update *gRPC.Update) (*gRPC.UpdateAck, error) {
+	n := s.n
+	n.cfg.Locker.Lock()
+	defer n.cfg.Locker.Unlock()
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if !n.follow(update.Epoch, update.Primary) {
+		return &gRPC.UpdateAck{Ok: false, Epoch: n.epoch, Seq: n.seq}, nil
+	}
+
+	switch {
+	case update.IsSnapshot:
+		if err := n.cfg.Restore(update.State); err != nil {
+			return nil, err
+		}
+		n.logger.Info("restored state", "seq", update.Seq, "primary", update.Primary)
+		n.seq = update.Seq
+	case update.Seq <= n.seq:
+		// we already have it, the primary must have retried
+	case update.Seq == n.seq+1:
+		if err := n.cfg.Apply(update.Entry); err != nil {
+			return nil, err
+		}
+		n.seq = update.Seq
+	default:
+		// we have missed something, the primary will send us the full state
+		return &gRPC.UpdateAck{Ok: false, Epoch: n.epoch, Seq: n.seq}, nil
+	}
+	return &gRPC.UpdateAck{Ok: true, Epoch: n.epoch, Seq: n.seq}, nil
+}
+
+// Heartbeat tells a backup that the primary is alive, and which backups are up to date.
+func (s *server) Heartbeat(ctx context.Context, beat *gRPC.Beat) (*gRPC.BeatAck, error) {
+	n := s.n
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if n.follow(beat.Epoch, beat.Primary) {
+		n.view = beat.Backups
+	}
+	return &gRPC.BeatAck{Epoch: n.epoch, Seq: n.seq}, nil
+}
+
+// follow is called when a message from the primary at epoch arrives.
+// It returns false if the sender is an old primary that should step down.
+// The caller must hold n.mu.
+func (n *Node) follow(epoch int64, primary string) bool {
+	if epoch < n.epoch {
+		return false
+	}
+	// two nodes can promote themselves in the same epoch if they time out at the same time,
+	// then the one with the smallest address stays primary. It moves on to a new epoch,
+	// so the other one sees that it is behind and steps down.
+	if epoch == n.epoch && n.primary && n.cfg.Addr < primary {
+		n.epoch++
+		return false
+	}
+
+	if n.primary {
+		n.logger.Info("another node is primary in a newer epoch, stepping down", "primary", primary, "epoch", epoch)
+	} else if n.primaryAddr != primary {
+		n.logger.Info("following primary", "primary", primary, "epoch", epoch)
+	}
+	n.primary = false
+	n.primaryAddr = primary
+	n.epoch = epoch
+	n.lastBeat = time.Now()
+	return true
+}
+
+// stepDown turns the primary into a backup after it has learned about a newer epoch.
+// The caller must hold n.mu.
+func (n *Node) stepDown(epoch int64) {
+	n.logger.Info("a backup knows a newer epoch, stepping down", "epoch", epoch)
+	n.primary = false
+	n.primaryAddr = ""
+	n.epoch = epoch
+	n.view = nil
+	n.lastBeat = time.Now()
+}
+
+// promote makes the backup the primary in a new epoch. The caller must hold n.mu.
+func (n *Node) promote() {
+	n.epoch++
+	n.primary = true
+	n.primaryAddr = n.cfg.Addr
+	n.logger.Warn("the primary stopped heartbeating, taking over", "epoch", n.epoch)
+
+	// some backups may have applied an update from the old primary that we never got,
+	// so no one is trusted to be up to date until they have received our full state
+	n.inSync = make(map[string]bool)
+	n.view = nil
+}
+
+// updateView rebuilds the view from the up to date backups. The caller must hold n.mu.
+func (n *Node) updateView() {
+	n.view = nil
+	for _, peer := range n.cfg.Peers {
+		if n.inSync[peer] {
+			n.view = append(n.view, peer)
+		}
+	}
+}
+
+func (n *Node) loop() {
+	ticker := time.NewTicker(n.cfg.HeartbeatInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-n.stop:
+			return
+		case <-ticker.C:
+		}
+
+		if n.IsPrimary() {
+			for _, peer := range n.cfg.Peers {
+				n.catchUp(peer)
+			}
+			n.heartbeat()
+		} else {
+			n.checkPrimary()
+		}
+	}
+}
+
+// checkPrimary promotes the backup if the primary has been silent for too long.
+// Backups further back in the view wait longer, so the first one gets to take over.
+func (n *Node) checkPrimary() {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	rank := -1
+	for i, peer := range n.view {
+		if peer == n.cfg.Addr {
+			rank = i
+		}
+	}
+	if rank < 0 {
+		// we are not up to date, so we may never take over
+		return
+	}
+	if time.Since(n.lastBeat) > n.cfg.FailoverTimeout*time.Duration(rank+1) {
+		n.promote()
+	}
+}
+
+// catchUp sends the full state to peer if it is not up to date.
+func (n *Node) catchUp(peer string) {
+	n.cfg.Locker.Lock()
+	defer n.cfg.Locker.Unlock()
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if !n.primary || n.inSync[peer] {
+		return
+	}
+
+	state, err := n.cfg.Snapshot()
+	if err != nil {
+		n.logger.Error("failed to take snapshot for backup", "backup", peer, "err", err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), n.cfg.FailoverTimeout)
+	defer cancel()
+	update := &gRPC.Update{Epoch: n.epoch, Primary: n.cfg.Addr, Seq: n.seq, IsSnapshot: true, State: state}
+	ack, err := n.client(peer).Replicate(ctx, update)
+	if err != nil {
+		return // probably not running, we try again on the next tick
+	}
+	if !ack.Ok {
+		if ack.Epoch > n.epoch {
+			n.stepDown(ack.Epoch)
+		}
+		return
+	}
+
+	n.logger.Info("backup is up to date", "backup", peer, "seq", n.seq)
+	n.inSync[peer] = true
+	n.updateView()
+}
+
+// heartbeat sends a heartbeat with the current view to every peer.
+func (n *Node) heartbeat() {
+	n.mu.Lock()
+	beat := &gRPC.Beat{Epoch: n.epoch, Primary: n.cfg.Addr, Seq: n.seq, Backups: n.view}
+	n.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), n.cfg.HeartbeatInterval)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	for _, peer := range n.cfg.Peers {
+		wg.Add(1)
+		go func(peer string) {
+			defer wg.Done()
+			ack, err := n.client(peer).Heartbeat(ctx, beat)
+			if err != nil {
+				return
+			}
+
+			n.mu.Lock()
+			defer n.mu.Unlock()
+			if ack.Epoch > n.epoch {
+				n.stepDown(ack.Epoch)
+			} else if n.inSync[peer] && ack.Seq < beat.Seq {
+				// it lost entries, most likely because it restarted
+				n.inSync[peer] = false
+				n.updateView()
+			}
+		}(peer)
+	}
+	wg.Wait()
+}
+
+// client returns a client for peer, dialing it the first time.
+func (n *Node) client(peer string) gRPC.ReplicationClient {
+	n.clientsMu.Lock()
+	defer n.clientsMu.Unlock()
+
+	if c, ok := n.clients[peer]; ok {
+		return c
+	}
+	// without grpc.WithBlock this doesn't wait for the connection,
+	// so it only fails if the options are wrong
+	conn, err := grpc.Dial(peer, n.cfg.DialOptions...)
+	if err != nil {
+		n.fatal("failed to dial peer", "peer", peer, "err", err)
+	}
+	c := gRPC.NewReplicationClient(conn)
+	n.clients[peer] = c
+	return c
+}
+
+// newLogger returns logger, or the default logger with the name of the node if it is nil,
+// and marks everything it logs as coming from replication.
+func newLogger(logger *slog.Logger, name string) *slog.Logger {
+	if logger == nil {
+		logger = slog.Default().With("node", name)
+	}
+	return logger.With("component", "replication")
+}
+
+// fatal logs msg and stops the program.
+func (n *Node) fatal(msg string, args ...any) {
+	n.logger.Error(msg, args...)
+	os.Exit(1)
+}
@@ -0,0 +1,277 @@
+// Package ricart implements the Ricart–Agrawala algorithm for mutual exclusion between nodes,
+// without a coordinator, so only one node at a time is in its critical section.
+//
+// A node that wants the critical section sends a Request with its lamport time to every other node,
+// and enters when all of them have sent a Reply. A node that gets a Request replies right away, unless
+// it is in the critical section itself, or wants it and asked first (the lowest time, then the lowest
+// address, goes first). Then it defers the reply until it leaves the critical section.
+//
+// Every node has to be running for anyone to enter, as the permission of every node is needed.
+package ricart
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/PatrickMatthiesen/DSYS-gRPC-template/lamport"
+	gRPC "github.com/PatrickMatthiesen/DSYS-gRPC-template/proto"
+
+	"google.golang.org/grpc"
+)
+
+// ErrStopped is returned by Enter when the node is stopped while it waits.
+var ErrStopped = errors.New("ricart: stopped")
+
+// ErrAlreadyWanted is returned by Enter when the node is already in, or waiting for, the critical section.
+var ErrAlreadyWanted = errors.New("ricart: already in or waiting for the critical section")
+
+type Config struct {
+	Name  string   // used in the log, if Logger is nil
+	Addr  string   // the address the other nodes can reach this node at, it is also the id of the node
+	Peers []string // the other nodes
+
+	Clock *lamport.Clock // the clock the requests are timestamped with (nil = a clock of its own)
+
+	RetryInterval time.Duration     // how long to wait before sending a message again to a node that didn't answer
+	DialOptions   []grpc.DialOption // used when dialing the peers
+
+	Logger *slog.Logger // where the node logs to (nil = slog.Default(), with the name of the node)
+}
+
+type state int
+
+const (
+	released state = iota // not in the critical section, and doesn't want it
+	wanted                // waiting for the replies
+	held                  // in the critical section
+)
+
+type Node struct {
+	cfg    Config
+	logger *slog.Logger
+
+	mu          sync.Mutex
+	state       state
+	requestTime int64                // the timestamp of our request, while wanted or held
+	replies     map[string]bool      // the peers that have replied to our request
+	allReplied  chan struct{}        // closed when every peer has replied
+	deferred    []*gRPC.MutexRequest // the requests to reply to when we leave the critical section
+
+	clientsMu sync.Mutex
+	clients   map[string]gRPC.MutualExclusionClient
+
+	stop chan struct{}
+}
+
+func New(cfg Config) *Node {
+	if cfg.Clock == nil {
+		cfg.Clock = &lamport.Clock{}
+	}
+	if cfg.RetryInterval == 0 {
+		cfg.RetryInterval = time.Second
+	}
+	return &Node{
+		cfg:     cfg,
+		logger:  newLogger(cfg.Logger, cfg.Name),
+		clients: make(map[string]gRPC.MutualExclusionClient),
+		stop:    make(chan struct{}),
+	}
+}
+
+// Stop stops the node. Enter returns ErrStopped, and deferred replies are never sent.
+func (n *Node) Stop() {
+	close(n.stop)
+}
+
+// Enter waits until the node may enter the critical section. Call Exit when done with it.
+// If ctx is done first, the request is given up and ctx.Err() returned.
+func (n *Node) Enter(ctx context.Context) error {
+	n.mu.Lock()
+	if n.state != released {
+		n.mu.Unlock()
+		return ErrAlreadyWanted
+	}
+	n.state = wanted
+	n.requestTime = n.cfg.Clock.Tick()
+	n.replies = make(map[string]bool)
+	n.allReplied = make(chan struct{})
+	if len(n.cfg.Peers) == 0 {
+		close(n.allReplied) // alone, so nobody has to agree
+	}
+	req := &gRPC.MutexRequest{Node: n.cfg.Addr, Timestamp: n.requestTime}
+	allReplied := n.allReplied
+	n.mu.Unlock()
+
+	n.logger.Debug("asking for the critical section", "timestamp", req.Timestamp)
+	start := time.Now()
+	sendCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	for _, peer := range n.cfg.Peers {
+		go n.send(sendCtx, peer, func(ctx context.Context, c gRPC.MutualExclusionClient) error {
+			_, err := c.Request(ctx, req)
+			return err
+		})
+	}
+
+	select {
+	case <-allReplied:
+	case <-ctx.Done():
+		n.giveUp()
+		return ctx.Err()
+	case <-n.stop:
+		return ErrStopped
+	}
+
+	n.mu.Lock()
+	n.state = held
+	n.mu.Unlock()
+	n.logger.Debug("entered the critical section", "timestamp", req.Timestamp, "waited", time.Since(start).Round(time.Millisecond))
+	return nil
+}
+
+// Exit leaves the critical section, and gives the deferred replies to the nodes waiting for it.
+func (n *Node) Exit() {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.state != held {
+		return
+	}
+	n.logger.Debug("left the critical section", "timestamp", n.requestTime, "deferred", len(n.deferred))
+	n.release()
+}
+
+// giveUp withdraws a request that is still waiting for replies. The ones that come later are ignored.
+func (n *Node) giveUp() {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.logger.Debug("gave up on the critical section", "timestamp", n.requestTime)
+	n.release()
+}
+
+// release goes back to not wanting the critical section. The caller must hold n.mu.
+func (n *Node) release() {
+	n.state = released
+	for _, req := range n.deferred {
+		n.reply(req.Node, req.Timestamp)
+	}
+	n.deferred = nil
+}
+
+// reply sends a reply to the request from peer with timestamp, in the background.
+func (n *Node) reply(peer string, timestamp int64) {
+	rep := &gRPC.MutexReply{Node: n.cfg.Addr, Timestamp: timestamp}
+	go n.send(context.Background(), peer, func(ctx context.Context, c gRPC.MutualExclusionClient) error {
+		_, err := c.Reply(ctx, rep)
+		return err
+	})
+}
+
+// send calls call on peer until it succeeds, as the algorithm can't do without any message.
+// It stops when ctx is done or the node is stopped.
+func (n *Node) send(ctx context.Context, peer string, call func(context.Context, gRPC.MutualExclusionClient) error) {
+	for {
+		callCtx, cancel := context.WithTimeout(ctx, n.cfg.RetryInterval)
+		err := call(callCtx, n.client(peer))
+		cancel()
+		if err == nil {
+			return
+		}
+		n.logger.Debug("failed to reach peer, trying again", "peer", peer, "err", err)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-n.stop:
+			return
+		case <-time.After(n.cfg.RetryInterval):
+		}
+	}
+}
+
+// server handles the MutualExclusion RPCs for a node.
+type server struct {
+	gRPC.UnimplementedMutualExclusionServer
+	n *Node
+}
+
+// Register registers the node's MutualExclusion service on s.
+func (n *Node) Register(s *grpc.Server) {
+	gRPC.RegisterMutualExclusionServer(s, &server{n: n})
+}
+
+// Request answers a request for the critical section from another node,
+// right away or when we leave the critical section ourselves.
+func (s *server) Request(ctx context.Context, req *gRPC.MutexRequest) (*gRPC.MutexAck, error) {
+	n := s.n
+	n.cfg.Clock.Witness(req.Timestamp)
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if n.state == held || (n.state == wanted && before(n.requestTime, n.cfg.Addr, req.Timestamp, req.Node)) {
+		n.logger.Debug("deferring reply", "peer", req.Node, "timestamp", req.Timestamp, "ours", n.requestTime)
+		n.deferred = append(n.deferred, req)
+	} else {
+		n.reply(req.Node, req.Timestamp)
+	}
+	return &gRPC.MutexAck{}, nil
+}
+
+// Reply counts the permission of another node for our request.
+func (s *server) Reply(ctx context.Context, rep *gRPC.MutexReply) (*gRPC.MutexAck, error) {
+	n := s.n
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	// a reply to a request we have given up on is ignored
+	if n.state != wanted || rep.Timestamp != n.requestTime || n.replies[rep.Node] {
+		return &gRPC.MutexAck{}, nil
+	}
+	n.replies[rep.Node] = true
+	if len(n.replies) == len(n.cfg.Peers) {
+		close(n.allReplied)
+	}
+	return &gRPC.MutexAck{}, nil
+}
+
+// before reports whether the request at time t1 from node1 goes before the one at t2 from node2.
+// The lamport times are compared first, and the addresses break ties, so all nodes agree on the order.
+func before(t1 int64, node1 string, t2 int64, node2 string) bool {
+	return t1 < t2 || (t1 == t2 && node1 < node2)
+}
+
+// client returns a client for peer, dialing it the first time.
+func (n *Node) client(peer string) gRPC.MutualExclusionClient {
+	n.clientsMu.Lock()
+	defer n.clientsMu.Unlock()
+
+	if c, ok := n.clients[peer]; ok {
+		return c
+	}
+	// without grpc.WithBlock this doesn't wait for the connection,
+	// so it only fails if the options are wrong
+	conn, err := grpc.Dial(peer, n.cfg.DialOptions...)
+	if err != nil {
+		n.fatal("failed to dial peer", "peer", peer, "err", err)
+	}
+	c := gRPC.NewMutualExclusionClient(conn)
+	n.clients[peer] = c
+	return c
+}
+
+func newLogger(logger *slog.Logger, name string) *slog.Logger {
+	if logger == nil {
+		logger = slog.Default().With("node", name)
+	}
+	return logger.With("component", "ricart")
+}
+
+// fatal logs msg and stops the program.
+func (n *Node) fatal(msg string, args ...any) {
+	n.logger.Error(msg, args...)
+	os.Exit(1)
+}
@@ -0,0 +1,393 @@
+// Package ringelection implements the Chang–Roberts algorithm for electing a leader among nodes in a ring.
+// Every node has an id, and the node with the highest id that is alive becomes the leader.
+//
+// The nodes are put in a ring ordered by id, and a node only ever sends election messages to the next node in it.
+// A node that starts an election sends its id around the ring. Every node passes on the highest id it has seen,
+// and drops ids lower than its own if it has already passed on its own. When a node gets its own id back, every
+// other node has seen it and passed it on, so it has the highest id, and it sends an Elected message around the ring.
+//
+// The leader sends a Heartbeat to everyone, and a node that doesn't hear from it for a while starts an election.
+// A node that can't reach the next node in the ring sends to the one after it instead.
+package ringelection
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	gRPC "github.com/PatrickMatthiesen/DSYS-gRPC-template/proto"
+
+	"google.golang.org/grpc"
+)
+
+type Peer struct {
+	ID   int64
+	Addr string
+}
+
+// Leader is the node that won the last election.
+type Leader Peer
+
+type Config struct {
+	Name  string // used in the log, if Logger is nil
+	ID    int64  // the id of this node, the highest one alive becomes the leader
+	Addr  string // the address the other nodes can reach this node at
+	Peers []Peer // the other nodes, in any order, the ring is ordered by id
+
+	HeartbeatInterval time.Duration // how often the leader sends heartbeats (default 1s)
+	FailureTimeout    time.Duration // how long without a heartbeat, or a finished election, before a new election (default 3s)
+	SendTimeout       time.Duration // how long to wait for the next node, before it is skipped (default 1s)
+
+	DialOptions []grpc.DialOption // used when dialing the peers
+
+	Logger *slog.Logger // where the node logs to (nil = slog.Default(), with the name of the node)
+}
+
+type Node struct {
+	cfg    Config
+	logger *slog.Logger
+	next   []Peer // the other nodes in the order they come after us in the ring
+
+	mu            sync.Mutex
+	leader        Leader
+	hasLeader     bool
+	participant   bool      // we have passed on an election message, and are waiting for the winner
+	started       time.Time // when we became a participant
+	lastHeartbeat time.Time // when we last heard from the leader
+	sent          int64     // the election messages we have sent, not counting heartbeats
+	sentBefore    int64     // sent when the messages were last counted for an election, see electionMessages
+
+	changes chan Leader
+
+	clientsMu sync.Mutex
+	clients   map[string]gRPC.RingElectionClient
+
+	stop chan struct{}
+}
+
+func New(cfg Config) *Node {
+	if cfg.HeartbeatInterval == 0 {
+		cfg.HeartbeatInterval = time.Second
+	}
+	if cfg.FailureTimeout == 0 {
+		cfg.FailureTimeout = 3 * time.Second
+	}
+	if cfg.SendTimeout == 0 {
+		cfg.SendTimeout = time.Second
+	}
+
+	// the nodes with higher ids come after us, then the ring goes around to the lowest one
+	next := append([]Peer(nil), cfg.Peers...)
+	sort.Slice(next, func(i, j int) bool {
+		ai, aj := next[i].ID > cfg.ID, next[j].ID > cfg.ID
+		if ai != aj {
+			return ai
+		}
+		return next[i].ID < next[j].ID
+	})
+
+	return &Node{
+		cfg:     cfg,
+		logger:  newLogger(cfg.Logger, cfg.Name),
+		next:    next,
+		changes: make(chan Leader, 1),
+		clients: make(map[string]gRPC.RingElectionClient),
+		stop:    make(chan struct{}),
+	}
+}
+
+// Start starts an election, as the node doesn't know of a leader yet, and starts the heartbeats.
+func (n *Node) Start() {
+	n.startElection("started")
+	go n.run()
+}
+
+// Stop stops the node. It stops sending heartbeats, if it is the leader, so the others elect a new one.
+func (n *Node) Stop() {
+	close(n.stop)
+}
+
+// Leader returns the current leader, and false if there is none, like during an election.
+func (n *Node) Leader() (Leader, bool) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.leader, n.hasLeader
+}
+
+// IsLeader reports whether this node is the leader.
+func (n *Node) IsLeader() bool {
+	leader, ok := n.Leader()
+	return ok && leader.ID == n.cfg.ID
+}
+
+// Changes returns a channel that gets the new leader every time it changes.
+// If the leader changes again before it is read, only the newest one is kept, so the reader is never behind.
+func (n *Node) Changes() <-chan Leader {
+	return n.changes
+}
+
+// run sends heartbeats while we are the leader, and starts an election if we don't hear from the leader,
+// or an election doesn't finish, like when a node died with the election message.
+func (n *Node) run() {
+	ticker := time.NewTicker(n.cfg.HeartbeatInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-n.stop:
+			return
+		case <-ticker.C:
+		}
+
+		n.mu.Lock()
+		isLeader := n.hasLeader && n.leader.ID == n.cfg.ID
+		gone := n.hasLeader && !isLeader && time.Since(n.lastHeartbeat) > n.cfg.FailureTimeout
+		stuck := !n.hasLeader && n.participant && time.Since(n.started) > n.cfg.FailureTimeout
+		n.mu.Unlock()
+
+		switch {
+		case isLeader:
+			n.heartbeat()
+		case gone:
+			n.startElection("the leader is gone")
+		case stuck:
+			n.startElection("the election didn't finish")
+		}
+	}
+}
+
+// startElection sends our id around the ring.
+func (n *Node) startElection(reason string) {
+	n.mu.Lock()
+	n.logger.Info("starting an election", "reason", reason)
+	n.hasLeader = false
+	n.participate()
+	n.mu.Unlock()
+
+	go n.forwardElection(n.cfg.ID, n.cfg.Addr)
+}
+
+// participate notes that we have passed on an election message. The caller must hold n.mu.
+func (n *Node) participate() {
+	n.participant = true
+	n.started = time.Now()
+}
+
+// election handles an election message with the candidate id, passing on the highest id.
+func (n *Node) election(id int64, addr string) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	switch {
+	case id > n.cfg.ID:
+		n.participate()
+		go n.forwardElection(id, addr)
+	case id < n.cfg.ID && !n.participant:
+		// we have a higher id, so we are the candidate now
+		n.participate()
+		go n.forwardElection(n.cfg.ID, n.cfg.Addr)
+	case id < n.cfg.ID:
+		// we have passed on our own id, or a higher one, already
+		n.logger.Debug("dropping election message", "candidate", id)
+	default:
+		// our id went all the way around, so nobody has a higher one
+		n.participant = false
+		n.setLeader(Leader{ID: n.cfg.ID, Addr: n.cfg.Addr})
+		go n.forwardElected(n.cfg.ID, n.cfg.Addr, n.electionMessages())
+	}
+}
+
+// elected handles an elected message with the new leader, and passes it on until it gets back to the leader.
+// On the way it adds up the messages every node sent in the election.
+func (n *Node) elected(id int64, addr string, messages int64) {
+	n.mu.Lock()
+	if id == n.cfg.ID {
+		// it went all the way around
+		n.mu.Unlock()
+		n.logger.Info("the election is over", "messages", messages)
+		return
+	}
+	n.participant = false
+	n.setLeader(Leader{ID: id, Addr: addr})
+	messages += n.electionMessages()
+	n.mu.Unlock()
+
+	go n.forwardElected(id, addr, messages)
+	if id < n.cfg.ID {
+		// we weren't there for the election, like when we start up, and we have a higher id
+		n.startElection("a node with a lower id is the leader")
+	}
+}
+
+// forwardElection sends the election message with the candidate id to the next node in the ring.
+// If none of the others can be reached, we are alone, so we are the leader.
+func (n *Node) forwardElection(id int64, addr string) {
+	msg := &gRPC.RingElectionMessage{Id: id, Addr: addr}
+	if !n.forward(n.countSent, func(ctx context.Context, c gRPC.RingElectionClient) error {
+		_, err := c.Election(ctx, msg)
+		return err
+	}) {
+		n.election(n.cfg.ID, n.cfg.Addr)
+	}
+}
+
+// forwardElected sends the elected message with the leader id to the next node in the ring,
+// with the messages sent in the election so far. The elected message is one of them,
+// so it is counted in the message itself.
+func (n *Node) forwardElected(id int64, addr string, messages int64) {
+	msg := &gRPC.RingElectionMessage{Id: id, Addr: addr, Messages: messages}
+	n.forward(func() { msg.Messages++ }, func(ctx context.Context, c gRPC.RingElectionClient) error {
+		_, err := c.Elected(ctx, msg)
+		return err
+	})
+}
+
+// forward calls call on the next node in the ring that answers, and reports whether one did.
+// count is called before every try, so the nodes that are skipped count as messages too. It is counted
+// before the message is sent, as the message can go around the ring and end the election before the call returns.
+func (n *Node) forward(count func(), call func(context.Context, gRPC.RingElectionClient) error) bool {
+	for _, p := range n.next {
+		count()
+		ctx, cancel := context.WithTimeout(context.Background(), n.cfg.SendTimeout)
+		err := call(ctx, n.client(p.Addr))
+		cancel()
+		if err == nil {
+			return true
+		}
+		n.logger.Debug("skipping a node that can't be reached", "peer", p.Addr, "err", err)
+
+		select {
+		case <-n.stop:
+			return true
+		default:
+		}
+	}
+	return false
+}
+
+// countSent counts an election message we send.
+func (n *Node) countSent() {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.sent++
+}
+
+// electionMessages returns the election messages we have sent since it was last called, and starts counting again.
+// The caller must hold n.mu.
+func (n *Node) electionMessages() int64 {
+	messages := n.sent - n.sentBefore
+	n.sentBefore = n.sent
+	return messages
+}
+
+// heartbeat tells every other node that we are still the leader.
+func (n *Node) heartbeat() {
+	msg := &gRPC.RingElectionMessage{Id: n.cfg.ID, Addr: n.cfg.Addr}
+	var wg sync.WaitGroup
+	for _, p := range n.next {
+		wg.Add(1)
+		go func(p Peer) {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(context.Background(), n.cfg.SendTimeout)
+			defer cancel()
+			if _, err := n.client(p.Addr).Heartbeat(ctx, msg); err != nil {
+				n.logger.Debug("failed to reach peer", "peer", p.Addr, "err", err)
+			}
+		}(p)
+	}
+	wg.Wait()
+}
+
+// setLeader makes leader the leader, and tells the reader of Changes. The caller must hold n.mu.
+func (n *Node) setLeader(leader Leader) {
+	n.lastHeartbeat = time.Now()
+	if n.hasLeader && n.leader == leader {
+		return
+	}
+	n.leader, n.hasLeader = leader, true
+	n.logger.Info("new leader", "leader", leader.ID, "addr", leader.Addr)
+
+	// replaces the leader that hasn't been read yet, if there is one
+	select {
+	case <-n.changes:
+	default:
+	}
+	n.changes <- leader
+}
+
+// server handles the RingElection RPCs for a node.
+type server struct {
+	gRPC.UnimplementedRingElectionServer
+	n *Node
+}
+
+// Register registers the node's RingElection service on s.
+func (n *Node) Register(s *grpc.Server) {
+	gRPC.RegisterRingElectionServer(s, &server{n: n})
+}
+
+func (s *server) Election(ctx context.Context, msg *gRPC.RingElectionMessage) (*gRPC.RingElectionAck, error) {
+	s.n.election(msg.Id, msg.Addr)
+	return &gRPC.RingElectionAck{}, nil
+}
+
+func (s *server) Elected(ctx context.Context, msg *gRPC.RingElectionMessage) (*gRPC.RingElectionAck, error) {
+	s.n.elected(msg.Id, msg.Addr, msg.Messages)
+	return &gRPC.RingElectionAck{}, nil
+}
+
+// Heartbeat notes that the leader is alive. A heartbeat from a leader with a lower id than ours
+// means we weren't there for the election, so we start one.
+func (s *server) Heartbeat(ctx context.Context, msg *gRPC.RingElectionMessage) (*gRPC.RingElectionAck, error) {
+	n := s.n
+	if msg.Id < n.cfg.ID {
+		n.mu.Lock()
+		participant := n.participant
+		n.mu.Unlock()
+		if !participant {
+			n.startElection("a node with a lower id is the leader")
+		}
+		return &gRPC.RingElectionAck{}, nil
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if !n.participant {
+		n.setLeader(Leader{ID: msg.Id, Addr: msg.Addr})
+	}
+	return &gRPC.RingElectionAck{}, nil
+}
+
+// client returns a client for peer, dialing it the first time.
+func (n *Node) client(peer string) gRPC.RingElectionClient {
+	n.clientsMu.Lock()
+	defer n.clientsMu.Unlock()
+
+	if c, ok := n.clients[peer]; ok {
+		return c
+	}
+	// without grpc.WithBlock this doesn't wait for the connection,
+	// so it only fails if the options are wrong
+	conn, err := grpc.Dial(peer, n.cfg.DialOptions...)
+	if err != nil {
+		n.fatal("failed to dial peer", "peer", peer, "err", err)
+	}
+	c := gRPC.NewRingElectionClient(conn)
+	n.clients[peer] = c
+	return c
+}
+
+func newLogger(logger *slog.Logger, name string) *slog.Logger {
+	if logger == nil {
+		logger = slog.Default().With("node", name)
+	}
+	return logger.With("component", "ringelection")
+}
+
+// fatal logs msg and stops the program.
+func (n *Node) fatal(msg string, args ...any) {
+	n.logger.Error(msg, args...)
+	os.Exit(1)
+}
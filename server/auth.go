@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"strings"
+	"time"
+
+	"github.com/PatrickMatthiesen/DSYS-gRPC-template/auth"
+	"github.com/PatrickMatthiesen/DSYS-gRPC-template/tlsutil"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// set with "-auth-secret <secret>" to only take calls with a token signed with the secret, made with "go run ./tokengen".
+// The servers make their own tokens when they call each other, so they all need the same secret.
+var authSecret = flag.String("auth-secret", "", "Secret the bearer tokens are signed with (empty = no authentication)")
+
+// how long the tokens the servers make for each other are valid
+const peerTokenTTL = time.Hour
+
+// the services only the other servers may call
+var peerServices = []string{"/proto.Replication/", "/proto.Raft/", "/proto.MutualExclusion/", "/proto.TokenRing/", "/proto.Bully/", "/proto.RingElection/", "/proto.GlobalSnapshot/"}
+
+// authUnaryInterceptors returns the interceptors that check the token of every call, if "-auth-secret" is set.
+func authUnaryInterceptors() []grpc.UnaryServerInterceptor {
+	if *authSecret == "" {
+		return nil
+	}
+	return []grpc.UnaryServerInterceptor{auth.UnaryServerInterceptor([]byte(*authSecret), publicServices...), requirePeer}
+}
+
+// authStreamInterceptors is the same as authUnaryInterceptors for streams.
+func authStreamInterceptors() []grpc.StreamServerInterceptor {
+	if *authSecret == "" {
+		return nil
+	}
+	return []grpc.StreamServerInterceptor{auth.StreamServerInterceptor([]byte(*authSecret), publicServices...)}
+}
+
+// requirePeer rejects calls to the services in peerServices that aren't made by one of the other servers,
+// so a client can't pretend to be a primary or raft leader.
+func requirePeer(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	for _, prefix := range peerServices {
+		if strings.HasPrefix(info.FullMethod, prefix) && !fromPeer(ctx) {
+			return nil, status.Error(codes.PermissionDenied, "only the other servers may call this")
+		}
+	}
+	return handler(ctx, req)
+}
+
+// peerAuthOptions returns the dial options that make the server send its own token to the other servers.
+func peerAuthOptions() []grpc.DialOption {
+	if *authSecret == "" {
+		return nil
+	}
+	return []grpc.DialOption{grpc.WithPerRPCCredentials(auth.SelfIssued([]byte(*authSecret), *serverName, peerTokenTTL))}
+}
+
+// fromPeer tells if the call was made by one of the other servers: it has a token the servers make for each other
+// with "-auth-secret", or the same certificate as this server with "-mtls". Otherwise there is no way to tell, so it isn't.
+func fromPeer(ctx context.Context) bool {
+	if claims, ok := auth.FromContext(ctx); ok {
+		return claims.Peer
+	}
+	name, ok := tlsutil.PeerIdentity(ctx)
+	return ok && serverIdentity != "" && name == serverIdentity
+}
+
+// callerName returns who made the call: the subject of the token with "-auth-secret",
+// the name in the client certificate with "-mtls", or else the name the client claims to have.
+// A call passed on by another server keeps the name it has, as that server has already replaced it.
+func callerName(ctx context.Context, claimed string) string {
+	if proxied(ctx) && fromPeer(ctx) {
+		return claimed
+	}
+	if claims, ok := auth.FromContext(ctx); ok {
+		return claims.Subject
+	}
+	if name, ok := tlsutil.PeerIdentity(ctx); ok {
+		return name
+	}
+	return claimed
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"testing"
+
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+// withCertificate returns a context for a call made with a verified certificate for name, like with "-mtls".
+func withCertificate(ctx context.Context, name string) context.Context {
+	cert := &x509.Certificate{Subject: pkix.Name{CommonName: name}}
+	info := credentials.TLSInfo{State: tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert}}}}
+	return peer.NewContext(ctx, &peer.Peer{AuthInfo: info})
+}
+
+func withProxied(ctx context.Context) context.Context {
+	return metadata.NewIncomingContext(ctx, metadata.Pairs(proxiedKey, "true"))
+}
+
+func TestCallerName(t *testing.T) {
+	defer func(old string) { serverIdentity = old }(serverIdentity)
+	serverIdentity = "server"
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		want string
+	}{
+		{"no identity", context.Background(), "bob"},
+		{"client certificate", withCertificate(context.Background(), "alice"), "alice"},
+		{"client certificate claiming to be proxied", withProxied(withCertificate(context.Background(), "alice")), "alice"},
+		{"proxied without any identity", withProxied(context.Background()), "bob"},
+		{"proxied by a server", withProxied(withCertificate(context.Background(), "server")), "bob"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := callerName(tt.ctx, "bob"); got != tt.want {
+				t.Errorf("callerName = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"io"
+	"log/slog"
+	"sync"
+
+	gRPC "github.com/PatrickMatthiesen/DSYS-gRPC-template/proto"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// how many messages can wait to be sent to a participant before we start dropping them
+const chatBuffer = 100
+
+// chatRoom keeps track of everyone in the chat, so messages can be sent to all of them.
+type chatRoom struct {
+	mutex        sync.Mutex
+	participants map[*participant]bool
+	closing      chan struct{} // closed when the server shuts down, made by closed
+}
+
+// participant is a client in the chat. Messages for it go through out,
+// so a slow client doesn't hold up the messages for everyone else.
+type participant struct {
+	name string
+	out  chan *gRPC.ChatMessage
+}
+
+// join adds p to the room and tells everyone else about it.
+// It returns false if the room is closed, as the server is shutting down.
+func (r *chatRoom) join(p *participant) bool {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.isClosed() {
+		return false
+	}
+	if r.participants == nil {
+		r.participants = make(map[*participant]bool)
+	}
+	r.participants[p] = true
+	r.broadcast(p, gRPC.ChatMessage_JOIN, p.name+" joined the chat")
+	return true
+}
+
+// leave removes p from the room, stops its sender and tells everyone else.
+func (r *chatRoom) leave(p *participant) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	delete(r.participants, p)
+	close(p.out)
+	r.broadcast(p, gRPC.ChatMessage_LEAVE, p.name+" left the chat")
+}
+
+// send sends a message from p to everyone else in the room.
+func (r *chatRoom) send(p *participant, message string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.broadcast(p, gRPC.ChatMessage_MESSAGE, message)
+}
+
+// closed returns a channel that is closed when the room is.
+func (r *chatRoom) closed() <-chan struct{} {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if r.closing == nil {
+		r.closing = make(chan struct{})
+	}
+	return r.closing
+}
+
+// isClosed reports whether close has been called. The caller must hold r.mutex.
+func (r *chatRoom) isClosed() bool {
+	select {
+	case <-r.closing:
+		return true
+	default:
+		return false // also when closing is nil, as receiving from a nil channel blocks
+	}
+}
+
+// close tells everyone the server is shutting down, and makes their Chat calls return,
+// so the server doesn't wait for clients that could stay in the chat forever.
+func (r *chatRoom) close() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if r.isClosed() {
+		return
+	}
+	if r.closing == nil {
+		r.closing = make(chan struct{})
+	}
+	r.broadcast(&participant{name: "server"}, gRPC.ChatMessage_LEAVE, "the server is shutting down")
+	close(r.closing)
+}
+
+// broadcast queues a message for everyone but from. The caller must hold r.mutex.
+func (r *chatRoom) broadcast(from *participant, kind gRPC.ChatMessage_Kind, message string) {
+	for p := range r.participants {
+		if p == from {
+			continue
+		}
+		msg := &gRPC.ChatMessage{ClientName: from.name, Message: message, Kind: kind}
+		select {
+		case p.out <- msg:
+		default:
+			slog.Warn("chat participant is too slow, dropping a message", "participant", p.name, "from", from.name)
+		}
+	}
+}
+
+func (s *Server) Chat(stream gRPC.Template_ChatServer) error {
+	// the client starts by telling us who it is
+	first, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+	if first.Kind != gRPC.ChatMessage_JOIN || first.ClientName == "" {
+		return status.Error(codes.InvalidArgument, "the first chat message has to be a JOIN with a name")
+	}
+
+	p := &participant{name: callerName(stream.Context(), first.ClientName), out: make(chan *gRPC.ChatMessage, chatBuffer)}
+	if !s.chat.join(p) {
+		return status.Error(codes.Unavailable, "the server is shutting down")
+	}
+	slog.InfoContext(stream.Context(), "chat participant joined", "participant", p.name)
+
+	// messages to the client are sent from their own goroutine,
+	// as the one below is busy waiting for messages from the client
+	sendErr := make(chan error, 1)
+	go func() {
+		for msg := range p.out {
+			if err := stream.Send(msg); err != nil {
+				sendErr <- err
+				// keep draining until leave closes the channel, so broadcasts never block on us
+				for range p.out {
+				}
+				return
+			}
+		}
+		sendErr <- nil
+	}()
+
+	// messages from the client are received in their own goroutine too,
+	// so we can stop waiting for them when the server shuts down
+	recvDone := make(chan struct{})
+	go func() {
+		defer close(recvDone)
+		for {
+			msg, err := stream.Recv()
+			if err == io.EOF {
+				return // the client left the chat
+			}
+			if err != nil {
+				select {
+				case <-s.chat.closed(): // the server cut the stream off, as it is shutting down
+				default:
+					slog.WarnContext(stream.Context(), "lost connection to chat participant", "participant", p.name, "err", err)
+				}
+				return
+			}
+			slog.InfoContext(stream.Context(), "chat message", "participant", p.name, "message", msg.Message)
+			s.chat.send(p, msg.Message)
+		}
+	}()
+
+	select {
+	case <-recvDone:
+	case <-s.chat.closed():
+		// the messages already queued, like the one saying the server shuts down, are still sent before we return
+	}
+
+	s.chat.leave(p)
+	slog.InfoContext(stream.Context(), "chat participant left", "participant", p.name)
+	return <-sendErr
+}
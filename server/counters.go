@@ -0,0 +1,214 @@
+package main
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+
+	gRPC "github.com/PatrickMatthiesen/DSYS-gRPC-template/proto"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// defaultKey is the counter an Amount without a key increments, so old clients keep working.
+const defaultKey = "default"
+
+// counterKey returns the key of the counter a request is for.
+func counterKey(key string) string {
+	if key == "" {
+		return defaultKey
+	}
+	return key
+}
+
+// counters are the named counters the clients increment.
+// Every counter has its own lock, so changes to different counters don't wait for each other.
+type counters struct {
+	mutex sync.RWMutex // guards m, the values are guarded by the lock of each counter
+	m     map[string]*counter
+}
+
+type counter struct {
+	mutex   sync.Mutex
+	value   int64
+	version uint64 // goes up by one every time the counter changes, 0 until the first change
+	deleted bool   // set when the counter is removed while someone waits for its lock
+}
+
+// proto returns c as a message. The caller must hold c.mutex.
+func (c *counter) proto(key string) *gRPC.Counter {
+	return &gRPC.Counter{Key: key, Value: c.value, Version: c.version}
+}
+
+// lock returns the counter with key, locked. If it doesn't exist it is made when create is true,
+// otherwise lock returns a NOT_FOUND error. The caller must unlock the counter when done with it.
+func (cs *counters) lock(key string, create bool) (*counter, error) {
+	for {
+		cs.mutex.RLock()
+		c := cs.m[key]
+		cs.mutex.RUnlock()
+
+		if c == nil {
+			if !create {
+				return nil, status.Errorf(codes.NotFound, "there is no counter %q", key)
+			}
+			cs.mutex.Lock()
+			if cs.m == nil {
+				cs.m = make(map[string]*counter)
+			}
+			if c = cs.m[key]; c == nil {
+				c = &counter{}
+				cs.m[key] = c
+			}
+			cs.mutex.Unlock()
+		}
+
+		c.mutex.Lock()
+		if !c.deleted {
+			return c, nil
+		}
+		// it was deleted while we waited, so look it up again
+		c.mutex.Unlock()
+	}
+}
+
+// unlock unlocks the counter with key, which the caller must have locked with lock.
+// A counter that was made by lock, but never changed, like when an increment fails, is removed again.
+func (cs *counters) unlock(key string, c *counter) {
+	if c.version == 0 && !c.deleted {
+		cs.remove(key, c)
+	}
+	c.mutex.Unlock()
+}
+
+// remove deletes the counter with key, which the caller must have locked with lock.
+func (cs *counters) remove(key string, c *counter) {
+	cs.mutex.Lock()
+	defer cs.mutex.Unlock()
+	delete(cs.m, key)
+	c.deleted = true
+}
+
+// get returns the counter with key.
+func (cs *counters) get(key string) (*gRPC.Counter, error) {
+	c, err := cs.lock(key, false)
+	if err != nil {
+		return nil, err
+	}
+	defer c.mutex.Unlock()
+	return c.proto(key), nil
+}
+
+// list returns the counters with keys starting with prefix, ordered by key.
+func (cs *counters) list(prefix string) []*gRPC.Counter {
+	cs.mutex.RLock()
+	keys := make([]string, 0, len(cs.m))
+	for key := range cs.m {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	cs.mutex.RUnlock()
+	sort.Strings(keys)
+
+	list := make([]*gRPC.Counter, 0, len(keys))
+	for _, key := range keys {
+		if counter, err := cs.get(key); err == nil { // it could have been deleted since
+			list = append(list, counter)
+		}
+	}
+	return list
+}
+
+// values returns the value and version of every counter. The caller must make sure nothing changes them at the same time.
+func (cs *counters) values() (values map[string]int64, versions map[string]uint64) {
+	cs.mutex.RLock()
+	defer cs.mutex.RUnlock()
+	values = make(map[string]int64, len(cs.m))
+	versions = make(map[string]uint64, len(cs.m))
+	for key, c := range cs.m {
+		values[key], versions[key] = c.value, c.version
+	}
+	return values, versions
+}
+
+// set replaces every counter with values and versions. The caller must make sure nothing uses them at the same time.
+func (cs *counters) set(values map[string]int64, versions map[string]uint64) {
+	cs.mutex.Lock()
+	defer cs.mutex.Unlock()
+	cs.m = make(map[string]*counter, len(values))
+	for key, value := range values {
+		c := &counter{value: value, version: versions[key]}
+		if c.version == 0 {
+			c.version = 1 // from a snapshot made before there were versions, but it has been changed at least once
+		}
+		cs.m[key] = c
+	}
+}
+
+// len returns the number of counters.
+func (cs *counters) len() int {
+	cs.mutex.RLock()
+	defer cs.mutex.RUnlock()
+	return len(cs.m)
+}
+
+func (s *Server) Get(ctx context.Context, key *gRPC.Key) (*gRPC.Counter, error) {
+	// reads are answered by whichever server gets them, so a backup or a raft follower can be a bit behind
+	return s.counters.get(counterKey(key.GetKey()))
+}
+
+func (s *Server) List(ctx context.Context, req *gRPC.ListRequest) (*gRPC.CounterList, error) {
+	return &gRPC.CounterList{Counters: s.counters.list(req.GetPrefix())}, nil
+}
+
+func (s *Server) Delete(ctx context.Context, key *gRPC.Key) (*gRPC.Counter, error) {
+	rec := record{Client: callerName(ctx, key.GetClientName()), Op: opDelete, Key: counterKey(key.GetKey())}
+	return s.update(ctx, rec, func(ctx context.Context, leader gRPC.TemplateClient) (*gRPC.Counter, error) {
+		return leader.Delete(ctx, key)
+	})
+}
+
+func (s *Server) Reset(ctx context.Context, key *gRPC.Key) (*gRPC.Counter, error) {
+	rec := record{Client: callerName(ctx, key.GetClientName()), Op: opReset, Key: counterKey(key.GetKey())}
+	return s.update(ctx, rec, func(ctx context.Context, leader gRPC.TemplateClient) (*gRPC.Counter, error) {
+		return leader.Reset(ctx, key)
+	})
+}
+
+func (s *Server) CompareAndSet(ctx context.Context, req *gRPC.CasRequest) (*gRPC.Counter, error) {
+	rec := record{
+		Client:   callerName(ctx, req.GetClientName()),
+		Op:       opCompareAndSet,
+		Key:      counterKey(req.GetKey()),
+		Expected: req.GetExpected(),
+		New:      req.GetNew(),
+	}
+	return s.update(ctx, rec, func(ctx context.Context, leader gRPC.TemplateClient) (*gRPC.Counter, error) {
+		return leader.CompareAndSet(ctx, req)
+	})
+}
+
+func (s *Server) IncrementIf(ctx context.Context, req *gRPC.ConditionalAmount) (*gRPC.Counter, error) {
+	if err := validateAmount("value", req.GetValue()); err != nil {
+		return nil, err
+	}
+	rec := record{
+		Client:  callerName(ctx, req.GetClientName()),
+		Op:      opIncrementIf,
+		Key:     counterKey(req.GetKey()),
+		Delta:   req.GetValue(),
+		Version: req.GetVersion(),
+	}
+	return s.update(ctx, rec, func(ctx context.Context, leader gRPC.TemplateClient) (*gRPC.Counter, error) {
+		return leader.IncrementIf(ctx, req)
+	})
+}
+
+// conditionFailed is the error for a CompareAndSet or IncrementIf whose condition doesn't hold.
+// The current counter goes along in the details, so the client can try again without a Get first.
+func conditionFailed(current *gRPC.Counter, format string, args ...any) error {
+	return withDetails(status.Newf(codes.FailedPrecondition, format, args...), current)
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"flag"
+	"time"
+
+	gRPC "github.com/PatrickMatthiesen/DSYS-gRPC-template/proto"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// the dedup table is part of the state every server holds, so these should be the same on all of them
+var dedupTTL = flag.Duration("dedup-ttl", 10*time.Minute, "How long the server remembers the last increment from a client that has gone quiet")
+var dedupMax = flag.Int("dedup-max", 1000, "Most clients the server remembers the last increment of, the ones heard from the longest ago are forgotten first")
+
+// errStaleIncrement is returned for an increment with a seq before the last one the server saw from the client.
+// The client has already moved on from it, so the server can't say what the answer was.
+var errStaleIncrement = status.Error(codes.FailedPrecondition, "the increment is older than the last one from the client")
+
+// session is what the server remembers about a client, so a retried increment isn't added twice.
+type session struct {
+	Seq      uint64 `json:"seq"`               // the last increment from the client
+	Value    int64  `json:"value"`             // what it was acknowledged with
+	Version  uint64 `json:"version,omitempty"` // and the version of the counter after it
+	LastSeen int64  `json:"lastSeen"`          // the time of the record it came in, in unix nanoseconds
+}
+
+// lookupSession checks if rec was already applied. If it was, done is true and
+// counter (or err) is what the client has to be answered with.
+func (s *Server) lookupSession(rec record) (counter *gRPC.Counter, done bool, err error) {
+	if rec.ClientID == "" {
+		return nil, false, nil // an old client, which doesn't send a client id
+	}
+
+	s.sessionMutex.Lock()
+	defer s.sessionMutex.Unlock()
+
+	sess, ok := s.sessions[rec.ClientID]
+	switch {
+	case !ok || rec.Seq > sess.Seq:
+		return nil, false, nil
+	case rec.Seq == sess.Seq:
+		return &gRPC.Counter{Key: rec.key(), Value: sess.Value, Version: sess.Version}, true, nil
+	default:
+		return nil, true, errStaleIncrement
+	}
+}
+
+// saveSession remembers that rec was applied and answered with counter,
+// and forgets the clients that are too old or too many.
+//
+// Only the times in the records are used, never the clock of the server,
+// so every server that applies the same records ends up with the same table.
+func (s *Server) saveSession(rec record, counter *gRPC.Counter) {
+	if rec.ClientID == "" {
+		return
+	}
+
+	s.sessionMutex.Lock()
+	defer s.sessionMutex.Unlock()
+	if s.sessions == nil {
+		s.sessions = make(map[string]session)
+	}
+	s.sessions[rec.ClientID] = session{Seq: rec.Seq, Value: counter.Value, Version: counter.Version, LastSeen: rec.Time}
+
+	oldest := rec.Time - dedupTTL.Nanoseconds()
+	for id, sess := range s.sessions {
+		if sess.LastSeen < oldest {
+			delete(s.sessions, id)
+		}
+	}
+
+	for len(s.sessions) > *dedupMax {
+		var oldestID string
+		for id, sess := range s.sessions {
+			if oldestID == "" || sess.LastSeen < s.sessions[oldestID].LastSeen ||
+				(sess.LastSeen == s.sessions[oldestID].LastSeen && id < oldestID) {
+				oldestID = id
+			}
+		}
+		delete(s.sessions, oldestID)
+	}
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"sort"
+	"testing"
+	"time"
+
+	gRPC "github.com/PatrickMatthiesen/DSYS-gRPC-template/proto"
+)
+
+func TestLookupSession(t *testing.T) {
+	var s Server
+	s.saveSession(record{ClientID: "a", Key: "k", Seq: 5, Time: 1}, &gRPC.Counter{Value: 42, Version: 3})
+
+	tests := []struct {
+		name  string
+		rec   record
+		done  bool
+		value int64
+		err   error
+	}{
+		{"client without an id", record{Seq: 5}, false, 0, nil},
+		{"new client", record{ClientID: "b", Seq: 1}, false, 0, nil},
+		{"next increment", record{ClientID: "a", Seq: 6}, false, 0, nil},
+		{"retry", record{ClientID: "a", Key: "k", Seq: 5}, true, 42, nil},
+		{"older increment", record{ClientID: "a", Seq: 4}, true, 0, errStaleIncrement},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			counter, done, err := s.lookupSession(tt.rec)
+			if done != tt.done || err != tt.err {
+				t.Fatalf("lookupSession = %v, %v, want %v, %v", done, err, tt.done, tt.err)
+			}
+			if tt.done && tt.err == nil {
+				if counter.GetKey() != "k" || counter.GetValue() != tt.value || counter.GetVersion() != 3 {
+					t.Errorf("lookupSession answered %v, want the counter from the first time", counter)
+				}
+			}
+		})
+	}
+}
+
+// clients returns the ids of the clients the server remembers, sorted.
+func clients(s *Server) []string {
+	var ids []string
+	for id := range s.sessions {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+func TestSaveSessionForgetsQuietClients(t *testing.T) {
+	setFlag(t, dedupTTL, time.Minute)
+	setFlag(t, dedupMax, 10)
+
+	var s Server
+	start := time.Now().UnixNano()
+	s.saveSession(record{ClientID: "a", Seq: 1, Time: start}, &gRPC.Counter{})
+	s.saveSession(record{ClientID: "b", Seq: 1, Time: start + (30 * time.Second).Nanoseconds()}, &gRPC.Counter{})
+	s.saveSession(record{ClientID: "c", Seq: 1, Time: start + (61 * time.Second).Nanoseconds()}, &gRPC.Counter{})
+
+	if got := clients(&s); len(got) != 2 || got[0] != "b" || got[1] != "c" {
+		t.Errorf("remembers %v, want [b c] after a has been quiet for longer than -dedup-ttl", got)
+	}
+}
+
+func TestSaveSessionForgetsOldestClients(t *testing.T) {
+	setFlag(t, dedupTTL, time.Hour)
+	setFlag(t, dedupMax, 2)
+
+	var s Server
+	s.saveSession(record{ClientID: "a", Seq: 1, Time: 1}, &gRPC.Counter{})
+	s.saveSession(record{ClientID: "b", Seq: 1, Time: 2}, &gRPC.Counter{})
+	s.saveSession(record{ClientID: "a", Seq: 2, Time: 3}, &gRPC.Counter{}) // a is heard from again
+	s.saveSession(record{ClientID: "c", Seq: 1, Time: 4}, &gRPC.Counter{})
+
+	if got := clients(&s); len(got) != 2 || got[0] != "a" || got[1] != "c" {
+		t.Errorf("remembers %v, want [a c] with -dedup-max 2", got)
+	}
+}
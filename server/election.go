@@ -0,0 +1,117 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log/slog"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/PatrickMatthiesen/DSYS-gRPC-template/bully"
+	"github.com/PatrickMatthiesen/DSYS-gRPC-template/logging"
+	"github.com/PatrickMatthiesen/DSYS-gRPC-template/ringelection"
+
+	"google.golang.org/grpc"
+)
+
+var election = flag.String("election", "", `How the servers in "-peers" elect a leader: "" (they don't), "bully" or "ring"`)
+var serverID = flag.Int64("id", 0, `The id of the server in elections, the highest one wins (default the port). The ids of the others go in "-peers" as id@host:port, or are their ports`)
+
+// startElection makes the server take part in electing a leader among the servers from "-peers",
+// and registers the service for it so the other servers can reach it.
+func (s *Server) startElection(grpcServer *grpc.Server) {
+	peers, err := electionPeers()
+	if err != nil {
+		logging.Fatal("bad peer", "err", err)
+	}
+	id, err := s.id()
+	if err != nil {
+		logging.Fatal("bad id", "err", err)
+	}
+
+	switch *election {
+	case "bully":
+		s.bully = bully.New(bully.Config{
+			Name:              s.name,
+			ID:                id,
+			Addr:              s.addr(),
+			Peers:             peers,
+			HeartbeatInterval: *heartbeatInterval,
+			FailureTimeout:    *failoverTimeout,
+			AnswerTimeout:     *heartbeatInterval,
+			DialOptions:       peerDialOptions(),
+			Logger:            slog.Default(), // already has the name of the server
+		})
+		s.bully.Register(grpcServer)
+		s.bully.Start()
+		go func() {
+			for leader := range s.bully.Changes() {
+				s.leaderChanged(leader.ID, leader.Addr)
+			}
+		}()
+	case "ring":
+		ring := make([]ringelection.Peer, len(peers))
+		for i, p := range peers {
+			ring[i] = ringelection.Peer(p)
+		}
+		s.ringElection = ringelection.New(ringelection.Config{
+			Name:              s.name,
+			ID:                id,
+			Addr:              s.addr(),
+			Peers:             ring,
+			HeartbeatInterval: *heartbeatInterval,
+			FailureTimeout:    *failoverTimeout,
+			SendTimeout:       *heartbeatInterval,
+			DialOptions:       peerDialOptions(),
+			Logger:            slog.Default(), // already has the name of the server
+		})
+		s.ringElection.Register(grpcServer)
+		s.ringElection.Start()
+		go func() {
+			for leader := range s.ringElection.Changes() {
+				s.leaderChanged(leader.ID, leader.Addr)
+			}
+		}()
+	default:
+		logging.Fatal("unknown election", "election", *election)
+	}
+}
+
+// leaderChanged is called every time the leader changes, with the id and address of the new one.
+// This is the place to act on a new leader, like sending the increments to it.
+func (s *Server) leaderChanged(id int64, addr string) {
+	slog.Info("the leader changed", "leader", id, "addr", addr, "us", addr == s.addr())
+}
+
+// id returns the id of the server in elections: "-id", or the port if it isn't set.
+func (s *Server) id() (int64, error) {
+	if *serverID != 0 {
+		return *serverID, nil
+	}
+	return strconv.ParseInt(s.port, 10, 64)
+}
+
+// electionPeers returns the servers from "-peers" with their ids.
+// A server is given as id@host:port, or just host:port, and then its port is its id, like for this server.
+func electionPeers() ([]bully.Peer, error) {
+	var list []bully.Peer
+	for _, p := range strings.Split(*peers, ",") {
+		if p = strings.TrimSpace(p); p == "" {
+			continue
+		}
+		idText, addr, found := strings.Cut(p, "@")
+		if !found {
+			addr = p
+			if _, idText, _ = net.SplitHostPort(p); idText == "" {
+				return nil, fmt.Errorf("%q has no port to use as its id", p)
+			}
+		}
+		id, err := strconv.ParseInt(idText, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%q has a bad id: %w", p, err)
+		}
+		list = append(list, bully.Peer{ID: id, Addr: addr})
+	}
+	return list, nil
+}
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/PatrickMatthiesen/DSYS-gRPC-template/chandylamport"
+	gRPC "github.com/PatrickMatthiesen/DSYS-gRPC-template/proto"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var globalSnapshotDir = flag.String("global-snapshot-dir", ".", "Folder the global snapshots are written to, by the server that takes them")
+var globalSnapshotTimeout = flag.Duration("global-snapshot-timeout", 10*time.Second, "How long to wait for the other servers to send their part of a global snapshot")
+var transferDemo = flag.Bool("transfer-demo", false, `Move random amounts of the "transfers" counter to the servers in "-peers", to see transfers on their way in a global snapshot`)
+
+// transferKey is the counter the transfer demo moves between the servers.
+const transferKey = "transfers"
+
+// transfer is the message a server sends another to move an amount of a counter to it.
+type transfer struct {
+	Key    string `json:"key"`
+	Amount int64  `json:"amount"`
+}
+
+// startGlobalSnapshot makes the server able to take global snapshots with the servers from "-peers",
+// and registers the service for it so the other servers can reach it.
+func (s *Server) startGlobalSnapshot(grpcServer *grpc.Server) {
+	s.globalSnapshot = chandylamport.New(chandylamport.Config{
+		Name:          s.name,
+		Addr:          s.addr(),
+		Peers:         peerList(),
+		State:         s.localState,
+		Deliver:       s.receiveTransfer,
+		RetryInterval: *heartbeatInterval,
+		DialOptions:   peerDialOptions(),
+		Logger:        slog.Default(), // already has the name of the server
+	})
+	s.globalSnapshot.Register(grpcServer)
+	s.globalSnapshot.Start()
+}
+
+// localState returns the values of the counters, as the part of a global snapshot from this server.
+func (s *Server) localState() []byte {
+	s.mutex.Lock() // nothing may change the counters while we read them
+	values, _ := s.counters.values()
+	s.mutex.Unlock()
+
+	data, err := json.Marshal(values)
+	if err != nil {
+		slog.Error("failed to marshal the counters", "err", err) // can't happen with a map of numbers
+	}
+	return data
+}
+
+// sendTransfer moves amount of the counter with key to peer. The amount is taken out here before it is sent,
+// so it is either here, on its way, or at peer, and a global snapshot sees it in exactly one of those places.
+func (s *Server) sendTransfer(peer, key string, amount int64) error {
+	payload, err := json.Marshal(transfer{Key: key, Amount: amount})
+	if err != nil {
+		return err
+	}
+	return s.globalSnapshot.Send(peer, payload, func() error {
+		rec := record{Client: "transfer to " + peer, Key: key, Delta: -amount, Time: time.Now().UnixNano()}
+		_, err := s.update(context.Background(), rec, noForward)
+		return err
+	})
+}
+
+// receiveTransfer adds a transfer from another server to the counter it is for.
+func (s *Server) receiveTransfer(from string, payload []byte) {
+	var t transfer
+	if err := json.Unmarshal(payload, &t); err != nil {
+		slog.Error("dropping bad transfer", "from", from, "err", err)
+		return
+	}
+	rec := record{Client: "transfer from " + from, Key: t.Key, Delta: t.Amount, Time: time.Now().UnixNano()}
+	if _, err := s.update(context.Background(), rec, noForward); err != nil {
+		// like if it would overflow. The amount is lost, which the next global snapshot shows
+		slog.Error("failed to add transfer", "from", from, "key", t.Key, "amount", t.Amount, "err", err)
+	}
+}
+
+// noForward is used for the changes a server makes itself, which can't be passed on to a raft leader.
+func noForward(context.Context, gRPC.TemplateClient) (*gRPC.Counter, error) {
+	return nil, status.Error(codes.FailedPrecondition, `transfers only work between servers without "-mode"`)
+}
+
+// runTransferDemo puts 100 in the transfers counter, and then keeps sending random amounts of it to the other servers.
+// The total of the counter over all the servers, and the transfers on their way, stays 100 times the number of servers.
+func (s *Server) runTransferDemo() {
+	peers := peerList()
+	if len(peers) == 0 {
+		slog.Warn(`the transfer demo needs other servers in "-peers"`)
+		return
+	}
+	rec := record{Client: "transfer demo", Key: transferKey, Delta: 100, Time: time.Now().UnixNano()}
+	if _, err := s.update(context.Background(), rec, noForward); err != nil {
+		slog.Error("failed to start the transfer demo", "err", err)
+		return
+	}
+
+	for {
+		time.Sleep(time.Duration(50+rand.Intn(100)) * time.Millisecond)
+
+		counter, err := s.counters.get(transferKey)
+		if err != nil || counter.Value <= 0 {
+			continue // we have given it all away, for now
+		}
+		peer := peers[rand.Intn(len(peers))]
+		amount := 1 + rand.Int63n(min(counter.Value, 10))
+		err = s.sendTransfer(peer, transferKey, amount)
+		if errors.Is(err, chandylamport.ErrStopped) {
+			return // the server is shutting down
+		}
+		if err != nil {
+			slog.Warn("failed to send transfer", "peer", peer, "amount", amount, "err", err)
+		}
+	}
+}
+
+// globalSnapshotFile is what a global snapshot is written as.
+type globalSnapshotFile struct {
+	*chandylamport.Global
+	Totals   map[string]int64 `json:"totals"`   // the value of each counter over all the servers, and the transfers on their way
+	InFlight int              `json:"inFlight"` // the number of transfers on their way
+}
+
+func (s *Server) Snapshot(ctx context.Context, req *gRPC.SnapshotRequest) (*gRPC.SnapshotResult, error) {
+	slog.InfoContext(ctx, "taking a global snapshot", "client", callerName(ctx, req.GetClientName()))
+	ctx, cancel := context.WithTimeout(ctx, *globalSnapshotTimeout)
+	defer cancel()
+	global, err := s.globalSnapshot.Snapshot(ctx)
+	if err != nil {
+		return nil, status.FromContextError(err).Err()
+	}
+
+	file := globalSnapshotFile{Global: global, Totals: make(map[string]int64)}
+	for node, local := range global.Nodes {
+		var values map[string]int64
+		if err := json.Unmarshal(local.State, &values); err != nil {
+			return nil, status.Errorf(codes.Internal, "bad state from %s: %v", node, err)
+		}
+		for key, value := range values {
+			file.Totals[key] += value
+		}
+		for from, messages := range local.Channels {
+			for _, m := range messages {
+				var t transfer
+				if err := json.Unmarshal(m, &t); err != nil {
+					return nil, status.Errorf(codes.Internal, "bad transfer from %s to %s: %v", from, node, err)
+				}
+				file.Totals[t.Key] += t.Amount
+				file.InFlight++
+			}
+		}
+	}
+
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to marshal the snapshot: %v", err)
+	}
+	name := filepath.Join(*globalSnapshotDir, "global-snapshot-"+strings.NewReplacer(":", "-", "/", "-").Replace(global.ID)+".json")
+	if err := os.WriteFile(name, data, 0o644); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to write the snapshot: %v", err)
+	}
+	slog.InfoContext(ctx, "wrote global snapshot", "file", name, "totals", fmt.Sprint(file.Totals), "in_flight", file.InFlight)
+
+	return &gRPC.SnapshotResult{Id: global.ID, File: name, Totals: file.Totals, InFlight: int64(file.InFlight)}, nil
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"log/slog"
+	"time"
+
+	gRPC "github.com/PatrickMatthiesen/DSYS-gRPC-template/proto"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+)
+
+// the services that don't need a token with "-auth-secret", so load balancers and tools like grpcurl can use them
+var publicServices = []string{"/grpc.health.v1.Health/", "/grpc.reflection."}
+
+// startHealth registers the standard health service, so clients and load balancers can ask if the server is serving,
+// and server reflection, so tools like grpcurl can list the services and call them:
+//
+//	grpcurl -plaintext localhost:5400 list
+//	grpcurl -plaintext -d '{"clientName": "me", "value": 1}' localhost:5400 proto.Template/Increment
+//
+// Must be called after the other services are registered.
+func (s *Server) startHealth(grpcServer *grpc.Server) {
+	s.health = health.NewServer()
+	healthpb.RegisterHealthServer(grpcServer, s.health)
+	reflection.Register(grpcServer)
+
+	// every service starts out serving, "" is the status of the server as a whole
+	for service := range grpcServer.GetServiceInfo() {
+		s.health.SetServingStatus(service, healthpb.HealthCheckResponse_SERVING)
+	}
+
+	// a backup rejects increments, so it tells the clients it isn't serving the Template service, and they go to the primary
+	if s.replication != nil {
+		go s.watchPrimary()
+	}
+}
+
+// watchPrimary keeps the health of the Template service up to date with whether this server is the primary.
+func (s *Server) watchPrimary() {
+	service := gRPC.Template_ServiceDesc.ServiceName
+	serving := true
+	for {
+		if primary := s.replication.IsPrimary(); primary != serving {
+			serving = primary
+			status := healthpb.HealthCheckResponse_NOT_SERVING
+			if serving {
+				status = healthpb.HealthCheckResponse_SERVING
+			}
+			slog.Info("health changed", "service", service, "status", status)
+			s.health.SetServingStatus(service, status)
+		}
+		time.Sleep(*heartbeatInterval / 2)
+	}
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"flag"
+	"log/slog"
+	"net/http"
+
+	"github.com/PatrickMatthiesen/DSYS-gRPC-template/metrics"
+
+	"google.golang.org/grpc"
+)
+
+// set with "-metrics-addr localhost:9100" and open http://localhost:9100/metrics to see how the server is doing
+var metricsAddr = flag.String("metrics-addr", "", "Address to serve Prometheus metrics on, ex. localhost:9100 (empty = no metrics)")
+
+// the metrics of the server, nil when "-metrics-addr" isn't set
+var registry *metrics.Registry
+var serverMetrics *metrics.ServerMetrics
+var metricsServer *http.Server
+
+// metricsServerOptions returns the options that measure every call to the server, if "-metrics-addr" is set.
+// They go before the other interceptors, so calls those reject are measured too.
+func metricsServerOptions() []grpc.ServerOption {
+	if *metricsAddr == "" {
+		return nil
+	}
+	registry = metrics.NewRegistry()
+	serverMetrics = metrics.NewServerMetrics(registry)
+	return []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(serverMetrics.UnaryServerInterceptor()),
+		grpc.ChainStreamInterceptor(serverMetrics.StreamServerInterceptor()),
+		grpc.StatsHandler(serverMetrics.StatsHandler()),
+	}
+}
+
+// startMetrics adds the metrics of s and serves all the metrics on "-metrics-addr".
+func (s *Server) startMetrics() {
+	if registry == nil {
+		return
+	}
+
+	registry.NewGaugeFunc("template_counters", "Number of counters on the server.", func() float64 {
+		return float64(s.counters.len())
+	})
+	registry.NewGaugeMapFunc("template_counter_value", "The current value of every counter.", "key", func() map[string]float64 {
+		values := make(map[string]float64)
+		for _, c := range s.counters.list("") {
+			values[c.Key] = float64(c.Value)
+		}
+		return values
+	})
+	registry.NewGaugeFunc("template_chat_participants", "Number of clients in the chat right now.", func() float64 {
+		s.chat.mutex.Lock()
+		defer s.chat.mutex.Unlock()
+		return float64(len(s.chat.participants))
+	})
+	registry.NewGaugeFunc("template_watchers", "Number of clients watching the counters right now.", func() float64 {
+		return float64(s.watchers.len())
+	})
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", registry.Handler())
+	metricsServer = &http.Server{Addr: *metricsAddr, Handler: mux}
+	go func() {
+		slog.Info("serving metrics", "url", "http://"+*metricsAddr+"/metrics")
+		if err := metricsServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			slog.Error("metrics stopped", "err", err)
+		}
+	}()
+}
+
+// stopMetrics stops serving the metrics, if they are served.
+func stopMetrics() error {
+	if metricsServer == nil {
+		return nil
+	}
+	return metricsServer.Close()
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"log/slog"
+	"math/rand"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/PatrickMatthiesen/DSYS-gRPC-template/ricart"
+	"github.com/PatrickMatthiesen/DSYS-gRPC-template/tokenring"
+
+	"google.golang.org/grpc"
+)
+
+var mutexMode = flag.Bool("mutex", false, `Take part in mutual exclusion (Ricart–Agrawala) with the servers from "-peers"`)
+var mutexDemo = flag.String("mutex-demo", "", `File with a number that every server increments in the critical section, to show that only one is in it at a time (needs "-mutex" or "-next")`)
+var mutexDemoRounds = flag.Int("mutex-demo-rounds", 10, `How many times each server enters the critical section with "-mutex-demo"`)
+
+var ringNext = flag.String("next", "", "Take part in a token ring, passing the token on to the server at this address")
+var tokenTimeout = flag.Duration("token-timeout", 5*time.Second, `How long nobody may have seen the token before a new one is made, with "-next"`)
+
+// criticalSection is what the demo needs from the mutual exclusion algorithms.
+type criticalSection interface {
+	Enter(ctx context.Context) error
+	Exit()
+}
+
+// startMutex makes the server take part in mutual exclusion with the servers from "-peers",
+// and registers the MutualExclusion service so the other servers can reach it.
+func (s *Server) startMutex(grpcServer *grpc.Server) {
+	s.ricart = ricart.New(ricart.Config{
+		Name:          s.name,
+		Addr:          s.addr(),
+		Peers:         peerList(),
+		Clock:         &clock,
+		RetryInterval: *heartbeatInterval,
+		DialOptions:   peerDialOptions(),
+		Logger:        slog.Default(), // already has the name of the server
+	})
+	s.ricart.Register(grpcServer)
+}
+
+// startTokenRing makes the server part of a token ring, where it passes the token on to "-next",
+// and registers the TokenRing service so the server before it can reach it.
+func (s *Server) startTokenRing(grpcServer *grpc.Server) {
+	s.ring = tokenring.New(tokenring.Config{
+		Name:          s.name,
+		Addr:          s.addr(),
+		Next:          *ringNext,
+		TokenTimeout:  *tokenTimeout,
+		RetryInterval: *heartbeatInterval,
+		DialOptions:   peerDialOptions(),
+		Logger:        slog.Default(), // already has the name of the server
+	})
+	s.ring.Register(grpcServer)
+	s.ring.Start()
+}
+
+// leaveTokenRing takes the server out of the token ring, so the others don't have to find out it is gone.
+// It waits for the token, which should come around within "-token-timeout".
+func (s *Server) leaveTokenRing() {
+	ctx, cancel := context.WithTimeout(context.Background(), *tokenTimeout)
+	defer cancel()
+	if err := s.ring.Leave(ctx); err != nil {
+		slog.Warn("failed to leave the token ring", "err", err)
+	}
+}
+
+// runMutexDemo enters the critical section "-mutex-demo-rounds" times, and adds one to the number in "-mutex-demo" each time.
+// Reading the number, waiting a bit and writing it back loses increments if two servers do it at once,
+// so the file ends up at the number of servers times the rounds only if they took turns.
+func (s *Server) runMutexDemo() {
+	var cs criticalSection = s.ricart
+	if s.ring != nil {
+		cs = s.ring
+	}
+
+	var waited time.Duration
+	for round := 1; round <= *mutexDemoRounds; round++ {
+		start := time.Now()
+		if err := cs.Enter(context.Background()); err != nil {
+			if !errors.Is(err, ricart.ErrStopped) && !errors.Is(err, tokenring.ErrStopped) {
+				slog.Error("failed to enter the critical section", "err", err)
+			}
+			return
+		}
+		wait := time.Since(start)
+		waited += wait
+
+		value, err := incrementFile(*mutexDemo)
+		cs.Exit()
+		if err != nil {
+			slog.Error("failed to increment the shared file", "file", *mutexDemo, "err", err)
+			return
+		}
+		slog.Info("incremented the shared file in the critical section", "round", round, "value", value, "waited", wait.Round(time.Millisecond))
+
+		// gives the others a chance to ask first
+		time.Sleep(time.Duration(rand.Intn(100)) * time.Millisecond)
+	}
+	slog.Info("mutex demo done", "rounds", *mutexDemoRounds, "average_wait", (waited / time.Duration(*mutexDemoRounds)).Round(time.Millisecond))
+}
+
+// incrementFile adds one to the number in path, and returns the new number.
+// It makes a marker file next to it while it works, so two servers in the critical section at once get noticed.
+func incrementFile(path string) (int64, error) {
+	marker, err := os.OpenFile(path+".held", os.O_CREATE|os.O_EXCL, 0o644)
+	if errors.Is(err, os.ErrExist) {
+		slog.Error("another server is in the critical section at the same time", "marker", path+".held")
+	}
+	if err != nil {
+		return 0, err
+	}
+	marker.Close()
+	defer os.Remove(path + ".held")
+
+	var value int64
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return 0, err
+	}
+	if text := strings.TrimSpace(string(data)); text != "" {
+		if value, err = strconv.ParseInt(text, 10, 64); err != nil {
+			return 0, err
+		}
+	}
+
+	// the longer it takes, the more likely it is that an increment gets lost without mutual exclusion
+	time.Sleep(50 * time.Millisecond)
+	value++
+	return value, os.WriteFile(path, []byte(strconv.FormatInt(value, 10)+"\n"), 0o644)
+}
@@ -0,0 +1,247 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"log/slog"
+
+	gRPC "github.com/PatrickMatthiesen/DSYS-gRPC-template/proto"
+	"github.com/PatrickMatthiesen/DSYS-gRPC-template/wal"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// set with "-data-dir <folder>" to keep the value between restarts. Without it everything lives in memory.
+var dataDir = flag.String("data-dir", "", "Folder for the write-ahead log and snapshots (empty = no persistence)")
+var snapshotEvery = flag.Int("snapshot-every", 100, "Number of logged increments between snapshots")
+
+// the ops a record can have
+const (
+	opIncrement = ""       // adds Delta to the counter, and makes it if it doesn't exist
+	opDelete    = "delete" // removes the counter
+	opReset     = "reset"  // sets the counter back to 0
+
+	// the conditional ones fail without changing anything when the condition doesn't hold
+	opCompareAndSet = "cas"   // sets the counter to New if it is Expected
+	opIncrementIf   = "incif" // adds Delta to the counter if it is at Version
+)
+
+// record is what gets written to the write-ahead log for every change to a counter.
+type record struct {
+	Client string `json:"client"`
+	Op     string `json:"op,omitempty"`
+	Key    string `json:"key,omitempty"` // empty in records from before there were more counters, which means defaultKey
+	Delta  int64  `json:"delta,omitempty"`
+
+	// the conditions of the conditional ops
+	Expected int64  `json:"expected,omitempty"`
+	New      int64  `json:"new,omitempty"`
+	Version  uint64 `json:"version,omitempty"`
+
+	// set when the client sends them, so the increment is only applied once
+	ClientID string `json:"clientId,omitempty"`
+	Seq      uint64 `json:"seq,omitempty"`
+	Time     int64  `json:"time,omitempty"` // when the increment was accepted, in unix nanoseconds
+}
+
+// state is what gets written to a snapshot, it holds everything the server needs to start again.
+type state struct {
+	Counters map[string]int64   `json:"counters"`
+	Versions map[string]uint64  `json:"versions,omitempty"`
+	Value    int64              `json:"value,omitempty"` // the only counter in snapshots from before there were more, see restore
+	Sessions map[string]session `json:"sessions,omitempty"`
+}
+
+// recover opens the write-ahead log in dir and rebuilds the server state from it.
+// Must be called before the server starts serving.
+func (s *Server) recover(dir string) error {
+	l, snapshot, records, err := wal.Open(dir)
+	if err != nil {
+		return err
+	}
+
+	// s.wal isn't set yet, so this doesn't write the snapshot again
+	if snapshot != nil {
+		if err := s.restore(snapshot); err != nil {
+			l.Close()
+			return err
+		}
+	}
+
+	for _, data := range records {
+		var rec record
+		if err := json.Unmarshal(data, &rec); err != nil {
+			l.Close()
+			return err
+		}
+		s.apply(rec) // a record that fails, like a stale increment, failed the same way when it was logged, so it is fine to ignore it here
+	}
+
+	s.wal = l
+	s.sinceSnapshot.Store(int64(len(records)))
+	slog.Info("recovered state", "counters", s.counters.len(), "dir", dir, "records_after_snapshot", len(records))
+	return nil
+}
+
+// persist appends rec to the write-ahead log. The caller must hold s.mutex,
+// at least for reading, and the lock of the counter, and apply rec right after.
+func (s *Server) persist(rec record) error {
+	if s.wal == nil {
+		return nil
+	}
+
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	if err := s.wal.Append(data); err != nil {
+		return err
+	}
+	s.sinceSnapshot.Add(1)
+	return nil
+}
+
+// snapshotIfDue is maybeSnapshot for callers that don't hold s.mutex.
+func (s *Server) snapshotIfDue() {
+	if s.wal == nil || s.sinceSnapshot.Load() < int64(*snapshotEvery) {
+		return // checked first, so most calls don't have to wait for the lock
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.maybeSnapshot()
+}
+
+// maybeSnapshot takes a snapshot when enough records have piled up in the log,
+// so recovery doesn't have to replay all of them. The caller must hold s.mutex for writing.
+func (s *Server) maybeSnapshot() {
+	if s.wal == nil || s.sinceSnapshot.Load() < int64(*snapshotEvery) {
+		return
+	}
+
+	snapshot, err := s.snapshot()
+	if err == nil {
+		err = s.wal.Snapshot(snapshot)
+	}
+	if err != nil {
+		// the records are still safe in the log, so we just try again next time
+		slog.Warn("failed to take snapshot", "err", err)
+		return
+	}
+	s.sinceSnapshot.Store(0)
+}
+
+// key returns the key of the counter rec changes.
+func (rec record) key() string {
+	return counterKey(rec.Key)
+}
+
+// creates reports whether rec makes the counter it changes when it doesn't exist.
+// A counter that doesn't exist is 0 at version 0 for the conditional ops.
+func (rec record) creates() bool {
+	return rec.Op == opIncrement || rec.Op == opCompareAndSet || rec.Op == opIncrementIf
+}
+
+// apply changes the state of the server according to rec, and returns the counter to answer the client with.
+// The caller must hold s.mutex, at least for reading.
+func (s *Server) apply(rec record) (*gRPC.Counter, error) {
+	c, err := s.counters.lock(rec.key(), rec.creates())
+	if err != nil {
+		return nil, err
+	}
+	defer s.counters.unlock(rec.key(), c)
+	return s.applyTo(c, rec)
+}
+
+// applyTo is apply for a counter the caller has already locked.
+// An increment that was already applied is not applied again, it gets the same answer as the first time,
+// and a change that fails the checks in check isn't applied at all.
+// Deleting or resetting a counter answers with the value it had.
+// Every change is published to the watchers, see watch.go.
+func (s *Server) applyTo(c *counter, rec record) (*gRPC.Counter, error) {
+	if rec.Op == opIncrement {
+		if counter, done, err := s.lookupSession(rec); done {
+			return counter, err
+		}
+	}
+	if err := s.check(c, rec); err != nil {
+		return nil, err
+	}
+
+	before := c.proto(rec.key())
+	switch rec.Op {
+	case opIncrement:
+		c.value += rec.Delta
+		c.version++
+		s.saveSession(rec, c.proto(rec.key()))
+	case opDelete:
+		s.counters.remove(rec.key(), c)
+		s.publishChange(rec, before, nil)
+		return before, nil
+	case opReset:
+		c.value = 0
+		c.version++
+		s.publishChange(rec, before, c.proto(rec.key()))
+		return before, nil
+	case opCompareAndSet:
+		c.value = rec.New
+		c.version++
+	case opIncrementIf:
+		c.value += rec.Delta
+		c.version++
+	default:
+		// every server fails on the same record, so they all stay the same
+		return nil, status.Errorf(codes.Internal, "unknown op %q", rec.Op)
+	}
+	after := c.proto(rec.key())
+	s.publishChange(rec, before, after)
+	return after, nil
+}
+
+// snapshot returns the full state of the server. The caller must hold s.mutex for writing.
+func (s *Server) snapshot() ([]byte, error) {
+	s.sessionMutex.Lock()
+	defer s.sessionMutex.Unlock()
+	values, versions := s.counters.values()
+	return json.Marshal(state{Counters: values, Versions: versions, Sessions: s.sessions})
+}
+
+// restore replaces the full state of the server with a snapshot from somewhere else,
+// and makes it the new snapshot in the log. The caller must hold s.mutex for writing.
+func (s *Server) restore(snapshot []byte) error {
+	var st state
+	if err := json.Unmarshal(snapshot, &st); err != nil {
+		return err
+	}
+	if s.wal != nil {
+		if err := s.wal.Snapshot(snapshot); err != nil {
+			return err
+		}
+		s.sinceSnapshot.Store(0)
+	}
+	if st.Counters == nil {
+		// a snapshot from before there were more counters, its value belongs to the default one
+		st.Counters = map[string]int64{defaultKey: st.Value}
+	}
+	s.counters.set(st.Counters, st.Versions)
+	s.watchers.reset() // the changes since the last snapshot aren't known, so the watchers have to start over
+	s.sessionMutex.Lock()
+	s.sessions = st.Sessions
+	s.sessionMutex.Unlock()
+	return nil
+}
+
+// applyEntry persists and applies a record that was encoded somewhere else,
+// like on the primary when running in primary-backup mode. The caller must hold s.mutex for writing.
+func (s *Server) applyEntry(entry []byte) error {
+	var rec record
+	if err := json.Unmarshal(entry, &rec); err != nil {
+		return err
+	}
+	if err := s.persist(rec); err != nil {
+		return err
+	}
+	s.apply(rec) // the primary has already answered the client, so the result (or a NOT_FOUND error) isn't needed
+	s.maybeSnapshot()
+	return nil
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+
+	"github.com/PatrickMatthiesen/DSYS-gRPC-template/logging"
+	gRPC "github.com/PatrickMatthiesen/DSYS-gRPC-template/proto"
+	"github.com/PatrickMatthiesen/DSYS-gRPC-template/raft"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// proxiedKey is set in the metadata of an increment a follower passes on to the leader,
+// so a server that wrongly thinks the other is the leader doesn't pass it back and forth.
+const proxiedKey = "raft-proxied"
+
+// startRaft makes the server part of a raft cluster with the servers from "-peers",
+// and registers the Raft service so the other servers can reach it.
+// The term, vote and log are kept in "-data-dir", if it is set.
+func (s *Server) startRaft(grpcServer *grpc.Server) {
+	node, err := raft.New(raft.Config{
+		Name:              s.name,
+		Addr:              s.addr(),
+		Peers:             peerList(),
+		Dir:               *dataDir,
+		HeartbeatInterval: *heartbeatInterval,
+		ElectionTimeout:   *failoverTimeout,
+		DialOptions:       peerDialOptions(),
+		Apply:             s.applyCommand,
+		Logger:            slog.Default(), // already has the name of the server
+	})
+	if err != nil {
+		logging.Fatal("failed to start raft", "err", err)
+	}
+	s.raft = node
+	s.raft.Register(grpcServer)
+	s.raft.Start()
+}
+
+// applyCommand is called by raft for every committed change, on every server.
+// It returns the value, which the leader answers the client with, or an error, like for a stale increment.
+// A retried increment can be in the log more than once, so the check for duplicates has to happen here.
+func (s *Server) applyCommand(command []byte) any {
+	var rec record
+	if err := json.Unmarshal(command, &rec); err != nil {
+		// every server would fail on the same entry, so it is skipped everywhere
+		slog.Error("skipping bad raft command", "err", err)
+		return status.Error(codes.Internal, "bad raft command: "+err.Error())
+	}
+
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	counter, err := s.apply(rec)
+	if err != nil {
+		return err
+	}
+	return counter
+}
+
+// updateRaft adds the change to the raft log and waits for it to be committed.
+// If this server isn't the leader, the call is passed on to the one that is with forward.
+func (s *Server) updateRaft(ctx context.Context, rec record, forward func(context.Context, gRPC.TemplateClient) (*gRPC.Counter, error)) (*gRPC.Counter, error) {
+	command, err := json.Marshal(rec)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	result, err := s.raft.Propose(ctx, command)
+	switch err {
+	case nil:
+		if err, ok := result.(error); ok {
+			return nil, err
+		}
+		return result.(*gRPC.Counter), nil
+	case raft.ErrNotLeader:
+		return s.proxyToLeader(ctx, rec, forward)
+	case raft.ErrLost:
+		return nil, status.Error(codes.Unavailable, "the leader changed before the change was committed, try again")
+	default:
+		return nil, status.FromContextError(err).Err()
+	}
+}
+
+// proxyToLeader passes a call on to the raft leader with forward and returns its answer.
+func (s *Server) proxyToLeader(ctx context.Context, rec record, forward func(context.Context, gRPC.TemplateClient) (*gRPC.Counter, error)) (*gRPC.Counter, error) {
+	leader := s.raft.Leader()
+	if leader == "" || proxied(ctx) {
+		return nil, status.Error(codes.Unavailable, "there is no raft leader right now, try again")
+	}
+
+	client, err := s.peerClient(leader)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	slog.InfoContext(ctx, "passing call on to the leader", "client", rec.Client, "key", rec.key(), "leader", leader)
+	return forward(metadata.AppendToOutgoingContext(ctx, proxiedKey, "true"), client)
+}
+
+// proxied tells if the call was passed on by another server.
+func proxied(ctx context.Context) bool {
+	md, _ := metadata.FromIncomingContext(ctx)
+	return len(md.Get(proxiedKey)) > 0
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+
+	"github.com/PatrickMatthiesen/DSYS-gRPC-template/replication"
+
+	"google.golang.org/grpc"
+)
+
+// startReplication makes the server part of a primary-backup group with the servers from "-peers",
+// and registers the Replication service so the other servers can reach it.
+func (s *Server) startReplication(grpcServer *grpc.Server) {
+	s.replication = replication.New(replication.Config{
+		Name:              s.name,
+		Addr:              s.addr(),
+		Peers:             peerList(),
+		Primary:           *mode == "primary",
+		HeartbeatInterval: *heartbeatInterval,
+		FailoverTimeout:   *failoverTimeout,
+		DialOptions:       peerDialOptions(),
+		Locker:            &s.mutex,
+		Apply:             s.applyEntry,
+		Snapshot:          s.snapshot,
+		Restore:           s.restore,
+		Logger:            slog.Default(), // already has the name of the server
+	})
+	s.replication.Register(grpcServer)
+	s.replication.Start()
+}
+
+// replicate sends rec to the backups, if the server is the primary of a primary-backup group.
+// The caller must hold s.mutex.
+func (s *Server) replicate(ctx context.Context, rec record) error {
+	if s.replication == nil {
+		return nil
+	}
+	entry, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	return s.replication.Replicate(ctx, entry)
+}
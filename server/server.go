@@ -5,16 +5,35 @@ import (
 	"flag"
 	"fmt"
 	"io"
-	"log"
+	"log/slog"
 	"net"
 	"os"
+	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	// this has to be the same as the go.mod module,
 	// followed by the path to the folder the proto file is in.
+	"github.com/PatrickMatthiesen/DSYS-gRPC-template/bully"
+	"github.com/PatrickMatthiesen/DSYS-gRPC-template/chandylamport"
+	"github.com/PatrickMatthiesen/DSYS-gRPC-template/lamport"
+	"github.com/PatrickMatthiesen/DSYS-gRPC-template/logging"
 	gRPC "github.com/PatrickMatthiesen/DSYS-gRPC-template/proto"
+	"github.com/PatrickMatthiesen/DSYS-gRPC-template/raft"
+	"github.com/PatrickMatthiesen/DSYS-gRPC-template/replication"
+	"github.com/PatrickMatthiesen/DSYS-gRPC-template/ricart"
+	"github.com/PatrickMatthiesen/DSYS-gRPC-template/ringelection"
+	"github.com/PatrickMatthiesen/DSYS-gRPC-template/tokenring"
+	"github.com/PatrickMatthiesen/DSYS-gRPC-template/tracing"
+	"github.com/PatrickMatthiesen/DSYS-gRPC-template/vclock"
+	"github.com/PatrickMatthiesen/DSYS-gRPC-template/wal"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
 )
 
 type Server struct {
@@ -22,8 +41,39 @@ type Server struct {
 	name                             string // Not required but useful if you want to name your server
 	port                             string // Not required but useful if your server needs to know what port it's listening to
 
-	incrementValue int64      // value that clients can increment.
-	mutex          sync.Mutex // used to lock the server to avoid race conditions.
+	counters counters // the counters that clients can increment, see counters.go
+	// used to lock the server to avoid race conditions. Changing a counter holds it for reading,
+	// so only the lock of the counter keeps others out, and anything that needs every counter
+	// to hold still, like a snapshot, holds it for writing.
+	mutex sync.RWMutex
+
+	wal           *wal.Log     // write-ahead log of every change, nil if the server runs without "-data-dir"
+	sinceSnapshot atomic.Int64 // number of records written to the log since the last snapshot
+
+	replication *replication.Node // nil unless the server runs in primary-backup mode
+	raft        *raft.Node        // nil unless the server runs in raft mode
+	ricart      *ricart.Node      // nil unless the server runs with "-mutex", see mutex.go
+	ring        *tokenring.Node   // nil unless the server runs with "-next", see mutex.go
+	bully       *bully.Node       // nil unless the server runs with "-election bully", see election.go
+
+	ringElection *ringelection.Node // nil unless the server runs with "-election ring", see election.go
+
+	globalSnapshot *chandylamport.Node // takes snapshots of all the servers together, see globalsnapshot.go
+
+	peerMutex   sync.Mutex                     // used to lock peerClients
+	peerClients map[string]gRPC.TemplateClient // connections to the other servers, see peerClient
+
+	causalMutex         sync.Mutex    // used to lock the two below
+	lastIncrementClock  vclock.VClock // vector clock of the last increment, to compare the next one with
+	lastIncrementClient string        // the client that sent it
+
+	sessionMutex sync.Mutex         // used to lock sessions
+	sessions     map[string]session // the last increment from every client, by client id, see dedup.go
+
+	chat     chatRoom // everyone connected to the Chat endpoint
+	watchers watchHub // everyone watching the counters, see watch.go
+
+	health *health.Server // answers health checks, see health.go
 }
 
 // flags are used to get arguments from the terminal. Flags take a value, a default value and a description of the flag.
@@ -31,64 +81,264 @@ type Server struct {
 var serverName = flag.String("name", "default", "Senders name") // set with "-name <name>" in terminal
 var port = flag.String("port", "5400", "Server port")           // set with "-port <port>" in terminal
 
-func main() {
+// used when running more than one server together, see "-mode"
+var mode = flag.String("mode", "", `How the server works with the servers in "-peers": "" (alone), "primary", "backup" or "raft"`)
+var peers = flag.String("peers", "", "Comma separated list of the other servers, ex. localhost:5401,localhost:5402")
+var heartbeatInterval = flag.Duration("heartbeat", time.Second, "How often the primary (or raft leader) heartbeats the other servers")
+var failoverTimeout = flag.Duration("failover-timeout", 3*time.Second, "How long a server waits for a heartbeat before it tries to take over")
+
+// the logical time of the server. The interceptors in launchServer and peerDialOptions
+// keep it up to date with every message that is sent and received.
+var clock lamport.Clock
 
-	// f := setLog() //uncomment this line to log to a log.txt file instead of the console
-	// defer f.Close()
+// the vector clock of the server, only used for calls from clients. Made in main, as it needs the name.
+var vectorClock *vclock.Clock
+var causalWait = flag.Duration("causal-wait", 0, "How long to hold back a client call that arrives before one it causally depends on (0 = don't)")
 
+// set with "-log-file log.txt" to log to a file instead of the console, see the logging package for the rest of the flags
+var logConfig = logging.RegisterFlags(flag.CommandLine)
+
+func main() {
 	// This parses the flags and sets the correct/given corresponding values.
 	flag.Parse()
 	fmt.Println(".:server is starting:.")
 
-	// launch the server
-	launchServer()
+	// every log line gets the name and port of the server, and the lamport time
+	_, logFile, err := logging.Setup(logConfig, clock.Now, "node", *serverName, "port", *port)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(exitFlags)
+	}
+	if err := checkFlags(); err != nil {
+		slog.Error("bad flags", "err", err)
+		os.Exit(exitFlags)
+	}
+	vectorClock = vclock.NewClock(*serverName)
+
+	// launch the server, it runs until it is stopped with Ctrl+C or fails, see shutdown.go
+	code := launchServer()
 
-	// code here is unreachable because launchServer occupies the current thread.
+	// os.Exit skips deferred calls, so the log is closed by hand
+	logFile.Close()
+	os.Exit(code)
 }
 
-func launchServer() {
-	log.Printf("Server %s: Attempts to create listener on port %s\n", *serverName, *port)
+// launchServer serves until the server is stopped, and returns the code to exit with.
+func launchServer() int {
+	slog.Info("creating listener")
 
 	// Create listener tcp on given port or default port 5400
 	list, err := net.Listen("tcp", fmt.Sprintf("localhost:%s", *port))
 	if err != nil {
-		log.Printf("Server %s: Failed to listen on port %s: %v", *serverName, *port, err) //If it fails to listen on the port, run launchServer method again with the next value/port in ports array
-		return
+		slog.Error("failed to listen", "err", err) //If it fails to listen on the port, run launchServer method again with the next value/port in ports array
+		return exitError
 	}
 
 	// makes gRPC server using the options
 	// you can add options here if you want or remove the options part entirely
-	var opts []grpc.ServerOption
+	// the interceptors run around every RPC, here they update the lamport clock
+	// the credentials are plaintext unless "-tls-cert" is set, see tls.go
+	// and calls are only checked for a token if "-auth-secret" is set, see auth.go
+	// every call is measured first if "-metrics-addr" is set, see metrics.go
+	// and traced next if "-trace-file" is set, see tracing.go
+	opts := append(metricsServerOptions(), tracingServerOptions()...)
+	opts = append(opts,
+		grpc.Creds(serverCredentials()),
+		grpc.ChainUnaryInterceptor(authUnaryInterceptors()...),
+		grpc.ChainStreamInterceptor(authStreamInterceptors()...),
+		grpc.ChainUnaryInterceptor(
+			lamport.UnaryServerInterceptor(&clock),
+			vclock.UnaryServerInterceptor(vectorClock, *causalWait),
+		),
+		grpc.ChainStreamInterceptor(
+			lamport.StreamServerInterceptor(&clock),
+			vclock.StreamServerInterceptor(vectorClock, *causalWait),
+		),
+	)
 	grpcServer := grpc.NewServer(opts...)
 
 	// makes a new server instance using the name and port from the flags.
 	server := &Server{
-		name:           *serverName,
-		port:           *port,
-		incrementValue: 0, // gives default value, but not sure if it is necessary
+		name: *serverName,
+		port: *port,
+	}
+
+	// load the value from the last run, if the server should remember it.
+	// in raft mode the value is rebuilt from the raft log instead.
+	if *dataDir != "" && *mode != "raft" {
+		if err := server.recover(*dataDir); err != nil {
+			logging.Fatal("failed to recover state", "dir", *dataDir, "err", err)
+		}
 	}
 
 	gRPC.RegisterTemplateServer(grpcServer, server) //Registers the server to the gRPC server.
+	server.startMetrics()
 
-	log.Printf("Server %s: Listening at %v\n", *serverName, list.Addr())
+	switch *mode {
+	case "":
+	case "primary", "backup":
+		server.startReplication(grpcServer)
+	case "raft":
+		server.startRaft(grpcServer)
+	default:
+		logging.Fatal("unknown mode", "mode", *mode)
+	}
+	if *mutexMode {
+		server.startMutex(grpcServer)
+	}
+	if *ringNext != "" {
+		server.startTokenRing(grpcServer)
+	}
+	if *election != "" {
+		server.startElection(grpcServer)
+	}
+	server.startGlobalSnapshot(grpcServer)
+	server.startHealth(grpcServer)
 
-	if err := grpcServer.Serve(list); err != nil {
-		log.Fatalf("failed to serve %v", err)
+	slog.Info("listening", "addr", list.Addr().String())
+	if *mutexDemo != "" {
+		go server.runMutexDemo()
 	}
-	// code here is unreachable because grpcServer.Serve occupies the current thread.
+	if *transferDemo {
+		go server.runTransferDemo()
+	}
+
+	return server.serve(grpcServer, list)
 }
 
 // The method format can be found in the pb.go file. If the format is wrong, the server type will give an error.
 func (s *Server) Increment(ctx context.Context, Amount *gRPC.Amount) (*gRPC.Ack, error) {
-	// locks the server ensuring no one else can increment the value at the same time.
-	// and unlocks the server when the method is done.
-	s.mutex.Lock()
-	defer s.mutex.Unlock()
+	// when the client is authenticated we use the name it was authenticated with, not the one it sends
+	if name := callerName(ctx, Amount.GetClientName()); name != Amount.GetClientName() {
+		Amount = proto.Clone(Amount).(*gRPC.Amount)
+		Amount.ClientName = name
+	}
+	span := tracing.SpanFromContext(ctx)
+	span.SetAttribute("client", Amount.GetClientName())
+	span.SetAttribute("key", counterKey(Amount.GetKey()))
+	span.SetAttribute("value", Amount.GetValue())
+	s.trackCausality(ctx, Amount.GetClientName())
+	if err := validateAmount("value", Amount.GetValue()); err != nil {
+		return nil, err
+	}
 
-	// increments the value by the amount given in the request,
+	// increments the counter by the amount given in the request,
 	// and returns the new value.
-	s.incrementValue += int64(Amount.GetValue())
-	return &gRPC.Ack{NewValue: s.incrementValue}, nil
+	counter, err := s.update(ctx, newRecord(Amount), func(ctx context.Context, leader gRPC.TemplateClient) (*gRPC.Counter, error) {
+		ack, err := leader.Increment(ctx, Amount)
+		return &gRPC.Counter{Value: ack.GetNewValue(), Version: ack.GetVersion()}, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &gRPC.Ack{NewValue: counter.Value, Version: counter.Version}, nil
+}
+
+// update makes the change in rec to a counter and returns the counter to answer the client with.
+// In raft mode a follower passes the call on to the leader with forward.
+func (s *Server) update(ctx context.Context, rec record, forward func(context.Context, gRPC.TemplateClient) (*gRPC.Counter, error)) (*gRPC.Counter, error) {
+	// in raft mode the change has to go through the raft log before it can be applied
+	if s.raft != nil {
+		return s.updateRaft(ctx, rec, forward)
+	}
+
+	if s.replication != nil {
+		// the backups have to get the changes in the same order the primary applies them,
+		// so in primary-backup mode they are made one at a time
+		s.mutex.Lock()
+		defer s.mutex.Unlock()
+		defer s.maybeSnapshot()
+
+		// in primary-backup mode only the primary takes changes.
+		// Unavailable makes the client fail over to the next server, until it finds the primary
+		if !s.replication.IsPrimary() {
+			return nil, status.Errorf(codes.Unavailable, "not the primary, the primary is %q", s.replication.Primary())
+		}
+	} else {
+		// changes to different counters are made at the same time, they only wait for a snapshot.
+		// the snapshot is taken after the lock is released, as it needs the lock for writing.
+		defer s.snapshotIfDue()
+		s.mutex.RLock()
+		defer s.mutex.RUnlock()
+	}
+
+	// locks the counter ensuring no one else can change it at the same time.
+	// and unlocks it when the method is done.
+	c, err := s.counters.lock(rec.key(), rec.creates())
+	if err != nil {
+		return nil, err
+	}
+	defer s.counters.unlock(rec.key(), c)
+
+	// a retry of an increment we have already applied gets the same answer again
+	if counter, done, err := s.lookupSession(rec); done {
+		if err != nil {
+			return nil, err
+		}
+		slog.InfoContext(ctx, "increment was already applied, answering it again", "client", rec.Client, "seq", rec.Seq)
+		return counter, nil
+	}
+
+	// a change that would fail when it is applied is rejected before it is replicated or logged
+	if err := s.check(c, rec); err != nil {
+		return nil, err
+	}
+
+	// the backups get the change before anything else, so they never miss something we have acknowledged.
+	if err := s.replicate(ctx, rec); err != nil {
+		slog.ErrorContext(ctx, "failed to replicate change", "client", rec.Client, "key", rec.key(), "err", err)
+		return nil, status.Error(codes.Unavailable, "failed to replicate the change")
+	}
+
+	// writes the change to the log before making it,
+	// so we never acknowledge something we would forget after a crash.
+	if err := s.persist(rec); err != nil {
+		slog.ErrorContext(ctx, "failed to persist change", "client", rec.Client, "key", rec.key(), "err", err)
+		return nil, status.Error(codes.Internal, "failed to persist the change")
+	}
+
+	return s.applyTo(c, rec)
+}
+
+// newRecord makes the record for an increment the server has just received.
+func newRecord(amount *gRPC.Amount) record {
+	return record{
+		Client:   amount.GetClientName(),
+		Key:      counterKey(amount.GetKey()),
+		Delta:    amount.GetValue(),
+		ClientID: amount.GetClientId(),
+		Seq:      amount.GetSeq(),
+		Time:     time.Now().UnixNano(),
+	}
+}
+
+// trackCausality logs how an increment relates to the one before it,
+// so we can see when clients increment without knowing about each other's increments.
+func (s *Server) trackCausality(ctx context.Context, client string) {
+	v, ok := vclock.FromContext(ctx)
+	if !ok {
+		return // the client doesn't send a vector clock
+	}
+
+	s.causalMutex.Lock()
+	defer s.causalMutex.Unlock()
+
+	if s.lastIncrementClock != nil {
+		var relation string
+		switch vclock.Compare(v, s.lastIncrementClock) {
+		case vclock.After:
+			relation = "happened after"
+		case vclock.Before:
+			relation = "happened before" // it was held up somewhere on the way
+		case vclock.Concurrent:
+			relation = "is concurrent with"
+		case vclock.Equal:
+			relation = "has the same clock as"
+		}
+		slog.InfoContext(ctx, "increment "+relation+" the last one",
+			"client", client, "vclock", v, "last_client", s.lastIncrementClient, "last_vclock", s.lastIncrementClock)
+	}
+	s.lastIncrementClock, s.lastIncrementClient = v, client
 }
 
 func (s *Server) SayHi(msgStream gRPC.Template_SayHiServer) error {
@@ -105,7 +355,7 @@ func (s *Server) SayHi(msgStream gRPC.Template_SayHiServer) error {
 			return err
 		}
 		// log the message
-		log.Printf("Received message from %s: %s", msg.ClientName, msg.Message)
+		slog.InfoContext(msgStream.Context(), "received message", "client", msg.ClientName, "message", msg.Message)
 	}
 
 	// be a nice server and say goodbye to the client :)
@@ -115,12 +365,63 @@ func (s *Server) SayHi(msgStream gRPC.Template_SayHiServer) error {
 	return nil
 }
 
+// addr is the address the other servers can reach this server at.
+func (s *Server) addr() string {
+	return fmt.Sprintf("localhost:%s", s.port)
+}
+
+// peerList splits the "-peers" flag into the addresses of the other servers.
+func peerList() []string {
+	var list []string
+	for _, p := range strings.Split(*peers, ",") {
+		// the id in id@host:port is only used in elections, see electionPeers
+		if _, addr, found := strings.Cut(p, "@"); found {
+			p = addr
+		}
+		if p = strings.TrimSpace(p); p != "" {
+			list = append(list, p)
+		}
+	}
+	return list
+}
+
+// peerClient returns a client for the Template service of another server, dialing it the first time.
+func (s *Server) peerClient(addr string) (gRPC.TemplateClient, error) {
+	s.peerMutex.Lock()
+	defer s.peerMutex.Unlock()
+
+	if client, ok := s.peerClients[addr]; ok {
+		return client, nil
+	}
+	conn, err := grpc.Dial(addr, peerDialOptions()...)
+	if err != nil {
+		return nil, err
+	}
+	if s.peerClients == nil {
+		s.peerClients = make(map[string]gRPC.TemplateClient)
+	}
+	client := gRPC.NewTemplateClient(conn)
+	s.peerClients[addr] = client
+	return client, nil
+}
+
+// peerDialOptions are the options used when a server dials one of the other servers.
+func peerDialOptions() []grpc.DialOption {
+	opts := []grpc.DialOption{
+		grpc.WithTransportCredentials(peerCredentials()),
+		grpc.WithChainUnaryInterceptor(lamport.UnaryClientInterceptor(&clock)),
+		grpc.WithChainStreamInterceptor(lamport.StreamClientInterceptor(&clock)),
+	}
+	opts = append(opts, peerTracingOptions()...)
+	return append(opts, peerAuthOptions()...)
+}
+
 // Get preferred outbound ip of this machine
 // Usefull if you have to know which ip you should dial, in a client running on an other computer
 func GetOutboundIP() net.IP {
 	conn, err := net.Dial("udp", "8.8.8.8:80")
 	if err != nil {
-		log.Fatal(err)
+		logging.Fatal("failed to find the outbound ip", "err", err)
 	}
 	defer conn.Close()
 
@@ -128,19 +429,3 @@ func GetOutboundIP() net.IP {
 
 	return localAddr.IP
 }
-
-// sets the logger to use a log.txt file instead of the console
-func setLog() *os.File {
-	// Clears the log.txt file when a new server is started
-	if err := os.Truncate("log.txt", 0); err != nil {
-		log.Printf("Failed to truncate: %v", err)
-	}
-
-	// This connects to the log file/changes the output of the log informaiton to the log.txt file.
-	f, err := os.OpenFile("log.txt", os.O_RDWR|os.O_CREATE|os.O_APPEND, 0666)
-	if err != nil {
-		log.Fatalf("error opening file: %v", err)
-	}
-	log.SetOutput(f)
-	return f
-}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"log/slog"
+	"net"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"google.golang.org/grpc"
+)
+
+// set with "-shutdown-timeout 30s" to give long calls, like increments waiting on a raft commit, more time to finish
+var shutdownTimeout = flag.Duration("shutdown-timeout", 10*time.Second, "How long to wait for the calls in progress to finish when the server is stopped, before they are cut off")
+
+// the exit codes of the server, so scripts can tell how it stopped
+const (
+	exitOK      = 0 // stopped by a signal, every call finished and the state was saved
+	exitError   = 1 // failed to start or to serve, or to save the state when stopping
+	exitFlags   = 2 // bad flags, like the flag package exits with
+	exitTimeout = 3 // stopped by a signal, but some calls didn't finish in time and were cut off
+)
+
+// serve serves on list until the server gets SIGINT (Ctrl+C) or SIGTERM, and then shuts down gracefully.
+// It returns the code the server should exit with.
+func (s *Server) serve(grpcServer *grpc.Server, list net.Listener) int {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	served := make(chan error, 1)
+	go func() { served <- grpcServer.Serve(list) }()
+
+	select {
+	case err := <-served:
+		slog.Error("failed to serve", "err", err)
+		if err := s.close(); err != nil {
+			slog.Error("failed to stop cleanly", "err", err)
+		}
+		return exitError
+	case <-ctx.Done():
+	}
+
+	// a second Ctrl+C kills the server right away, like it did before
+	stop()
+	return s.shutdown(grpcServer)
+}
+
+// shutdown stops the server gracefully: new connections are refused, the calls in progress
+// get "-shutdown-timeout" to finish, and then the state is saved and the files closed.
+func (s *Server) shutdown(grpcServer *grpc.Server) int {
+	slog.Info("shutting down", "timeout", shutdownTimeout.String())
+	code := exitOK
+
+	// the clients watching the health move on to another server right away,
+	// and the chats and watches are ended, as they would otherwise keep the server waiting until the timeout
+	s.health.Shutdown()
+	s.chat.close()
+	s.watchers.close()
+
+	// the token has to come to us to leave the ring, so it has to happen while we still take calls
+	if s.ring != nil {
+		s.leaveTokenRing()
+	}
+
+	drained := make(chan struct{})
+	go func() {
+		grpcServer.GracefulStop()
+		close(drained)
+	}()
+	select {
+	case <-drained:
+	case <-time.After(*shutdownTimeout):
+		slog.Warn("calls didn't finish in time, cutting them off")
+		grpcServer.Stop()
+		code = exitTimeout
+	}
+
+	if err := s.close(); err != nil {
+		slog.Error("failed to stop cleanly", "err", err)
+		code = exitError
+	}
+	slog.Info("stopped", "exit_code", code)
+	return code
+}
+
+// close stops everything the server runs besides the gRPC server, saves its state and closes its files.
+func (s *Server) close() error {
+	if s.replication != nil {
+		s.replication.Stop()
+	}
+	if s.raft != nil {
+		s.raft.Stop() // closes the raft log too
+	}
+	if s.ricart != nil {
+		s.ricart.Stop()
+	}
+	if s.ring != nil {
+		s.ring.Stop()
+	}
+	if s.bully != nil {
+		s.bully.Stop()
+	}
+	if s.ringElection != nil {
+		s.ringElection.Stop()
+	}
+	s.globalSnapshot.Stop()
+
+	var errs []error
+	s.mutex.Lock()
+	if s.wal != nil {
+		// everything is already in the log, but a snapshot makes the next start faster
+		if s.sinceSnapshot.Load() > 0 {
+			snapshot, err := s.snapshot()
+			if err == nil {
+				err = s.wal.Snapshot(snapshot)
+			}
+			if err != nil {
+				slog.Warn("failed to take snapshot", "err", err)
+			}
+		}
+		errs = append(errs, s.wal.Close())
+	}
+	s.mutex.Unlock()
+
+	errs = append(errs, stopTracing(), stopMetrics())
+	return errors.Join(errs...)
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"flag"
+
+	"github.com/PatrickMatthiesen/DSYS-gRPC-template/logging"
+	"github.com/PatrickMatthiesen/DSYS-gRPC-template/tlsutil"
+
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// set with "-tls-cert <file> -tls-key <file>" to serve over TLS, the certificates can be made with "go run ./certgen".
+// The same certificate is used when the server calls the other servers, so it has to be valid for clients too.
+var tlsCert = flag.String("tls-cert", "", "Certificate of the server (empty = no TLS)")
+var tlsKey = flag.String("tls-key", "", "Private key of the server certificate")
+var tlsCA = flag.String("tls-ca", "", "CA used to check the certificates of clients and the other servers (empty = the CAs of the system)")
+var mtls = flag.Bool("mtls", false, "Require clients to send a certificate signed by \"-tls-ca\", the name in it is then used instead of the name the client sends")
+
+// serverIdentity is the name in the certificate of the server with "-mtls". The other servers call with the same
+// certificate, so a caller with this name is one of them. It is "" without "-mtls".
+var serverIdentity string
+
+// serverCredentials returns the credentials launchServer serves with.
+func serverCredentials() credentials.TransportCredentials {
+	if *tlsCert == "" {
+		return insecure.NewCredentials()
+	}
+	creds, err := tlsutil.ServerCredentials(*tlsCert, *tlsKey, *tlsCA, *mtls)
+	if err != nil {
+		logging.Fatal("failed to set up TLS", "err", err)
+	}
+	if *mtls {
+		name, _, err := tlsutil.CertIdentity(*tlsCert)
+		if err != nil {
+			logging.Fatal("failed to read the name in the certificate", "err", err)
+		}
+		serverIdentity = name
+	}
+	return creds
+}
+
+// peerCredentials returns the credentials used to call the other servers,
+// which run with the same settings as this one.
+func peerCredentials() credentials.TransportCredentials {
+	if *tlsCert == "" {
+		return insecure.NewCredentials()
+	}
+	creds, err := tlsutil.ClientCredentials(*tlsCert, *tlsKey, *tlsCA, "")
+	if err != nil {
+		logging.Fatal("failed to set up TLS for the other servers", "err", err)
+	}
+	return creds
+}
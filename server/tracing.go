@@ -0,0 +1,54 @@
+package main
+
+import (
+	"flag"
+
+	"github.com/PatrickMatthiesen/DSYS-gRPC-template/logging"
+	"github.com/PatrickMatthiesen/DSYS-gRPC-template/tracing"
+
+	"google.golang.org/grpc"
+)
+
+// set with "-trace-file traces.json" to write a span for every call the server handles or makes.
+// Give the clients and the other servers the same file to get the whole trace in one place.
+var traceFile = flag.String("trace-file", "", "File to append the spans of every call to, as JSON lines (empty = no tracing)")
+
+// the tracer of the server, nil when "-trace-file" isn't set, which turns tracing off
+var tracer *tracing.Tracer
+var traceExporter *tracing.FileExporter
+
+// tracingServerOptions returns the options that make a span for every call to the server, if "-trace-file" is set.
+func tracingServerOptions() []grpc.ServerOption {
+	if *traceFile == "" {
+		return nil
+	}
+	exporter, err := tracing.NewFileExporter(*traceFile)
+	if err != nil {
+		logging.Fatal("failed to open the trace file", "err", err)
+	}
+	tracer = tracing.NewTracer(*serverName, exporter)
+	traceExporter = exporter
+	return []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(tracing.UnaryServerInterceptor(tracer)),
+		grpc.ChainStreamInterceptor(tracing.StreamServerInterceptor(tracer)),
+	}
+}
+
+// peerTracingOptions returns the dial options that continue the trace when the server calls the other servers.
+func peerTracingOptions() []grpc.DialOption {
+	if tracer == nil {
+		return nil
+	}
+	return []grpc.DialOption{
+		grpc.WithChainUnaryInterceptor(tracing.UnaryClientInterceptor(tracer)),
+		grpc.WithChainStreamInterceptor(tracing.StreamClientInterceptor(tracer)),
+	}
+}
+
+// stopTracing closes the trace file, if there is one.
+func stopTracing() error {
+	if traceExporter == nil {
+		return nil
+	}
+	return traceExporter.Close()
+}
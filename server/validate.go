@@ -0,0 +1,123 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+
+	gRPC "github.com/PatrickMatthiesen/DSYS-gRPC-template/proto"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/runtime/protoiface"
+)
+
+// the policy for increments. Like the dedup flags they are part of how the state changes,
+// so they should be the same on all the servers.
+var minAmount = flag.Int64("min-amount", math.MinInt64, "Smallest amount a single increment may add")
+var maxAmount = flag.Int64("max-amount", math.MaxInt64, "Largest amount a single increment may add")
+var nonNegative = flag.Bool("non-negative", false, "Reject changes that would make a counter go below 0")
+
+// errorDomain is the domain of the ErrorInfo details the server sends.
+const errorDomain = "template"
+
+// checkFlags makes sure the flags make sense together, so a typo doesn't reject every increment.
+func checkFlags() error {
+	if *minAmount > *maxAmount {
+		return fmt.Errorf(`"-min-amount" %d is larger than "-max-amount" %d`, *minAmount, *maxAmount)
+	}
+	if *mutexMode && *ringNext != "" {
+		return fmt.Errorf(`"-mutex" and "-next" can't be used together`)
+	}
+	if *mutexDemo != "" && !*mutexMode && *ringNext == "" {
+		return fmt.Errorf(`"-mutex-demo" needs "-mutex" or "-next"`)
+	}
+	if *transferDemo && *mode != "" {
+		return fmt.Errorf(`"-transfer-demo" only works with servers that have no "-mode", as the counters are the same on all the servers in a group`)
+	}
+	return nil
+}
+
+// validateAmount checks that an amount to add is within "-min-amount" and "-max-amount".
+// It doesn't depend on the counter, so it is checked when the call arrives.
+func validateAmount(field string, amount int64) error {
+	if amount >= *minAmount && amount <= *maxAmount {
+		return nil
+	}
+	st := status.Newf(codes.InvalidArgument, "%s must be between %d and %d, not %d", field, *minAmount, *maxAmount, amount)
+	return withDetails(st, &errdetails.BadRequest{FieldViolations: []*errdetails.BadRequest_FieldViolation{{
+		Field:       field,
+		Description: fmt.Sprintf("must be between %d and %d", *minAmount, *maxAmount),
+	}}})
+}
+
+// check returns why rec can't be applied to c, or nil if it can. It is called before rec is logged,
+// so nothing that fails is logged, and again when it is applied, for the records raft applies.
+// The caller must hold c.mutex.
+func (s *Server) check(c *counter, rec record) error {
+	current := c.proto(rec.key())
+	after := c.value
+	switch rec.Op {
+	case opIncrement, opIncrementIf:
+		if rec.Op == opIncrementIf && c.version != rec.Version {
+			return conditionFailed(current, "%s is at version %d, not %d", rec.key(), c.version, rec.Version)
+		}
+		sum, ok := add(c.value, rec.Delta)
+		if !ok {
+			return outOfRange(current, "OVERFLOW", strconv.FormatInt(rec.Delta, 10), "adding %d to %s would overflow, it is %d", rec.Delta, rec.key(), c.value)
+		}
+		after = sum
+	case opCompareAndSet:
+		if c.value != rec.Expected {
+			return conditionFailed(current, "%s is %d, not %d", rec.key(), c.value, rec.Expected)
+		}
+		after = rec.New
+	}
+
+	if *nonNegative && after < 0 && after < c.value {
+		return outOfRange(current, "NEGATIVE", difference(after, c.value), "%s can't go below 0, it is %d", rec.key(), c.value)
+	}
+	return nil
+}
+
+// add returns a + b, and false if it overflows an int64.
+func add(a, b int64) (int64, bool) {
+	sum := a + b
+	if (b > 0 && sum < a) || (b < 0 && sum > a) {
+		return 0, false
+	}
+	return sum, true
+}
+
+// difference returns a - b in base 10. It is worked out with big numbers,
+// as setting a large counter to a negative value is a change that doesn't fit in an int64.
+func difference(a, b int64) string {
+	return new(big.Int).Sub(big.NewInt(a), big.NewInt(b)).String()
+}
+
+// outOfRange is the error for a change that would take a counter out of the range it may have.
+// It depends on the value of the counter, so the current counter goes along in the details.
+func outOfRange(current *gRPC.Counter, reason, amount string, format string, args ...any) error {
+	st := status.Newf(codes.OutOfRange, format, args...)
+	return withDetails(st, &errdetails.ErrorInfo{
+		Reason: reason,
+		Domain: errorDomain,
+		Metadata: map[string]string{
+			"key":    current.Key,
+			"value":  strconv.FormatInt(current.Value, 10),
+			"amount": amount,
+		},
+	}, current)
+}
+
+// withDetails returns st as an error with details added. If they can't be added,
+// which only happens if they can't be marshalled, the error goes without them.
+func withDetails(st *status.Status, details ...protoiface.MessageV1) error {
+	if withDetails, err := st.WithDetails(details...); err == nil {
+		st = withDetails
+	}
+	return st.Err()
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"math"
+	"testing"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// setFlag sets a flag for the rest of the test.
+func setFlag[T any](t *testing.T, flag *T, value T) {
+	old := *flag
+	*flag = value
+	t.Cleanup(func() { *flag = old })
+}
+
+// errorInfo returns the ErrorInfo details of err, or nil if there are none.
+func errorInfo(err error) *errdetails.ErrorInfo {
+	for _, detail := range status.Convert(err).Details() {
+		if info, ok := detail.(*errdetails.ErrorInfo); ok {
+			return info
+		}
+	}
+	return nil
+}
+
+func TestCheck(t *testing.T) {
+	setFlag(t, nonNegative, true)
+
+	tests := []struct {
+		name   string
+		value  int64
+		rec    record
+		code   codes.Code
+		reason string
+		amount string
+	}{
+		{"increment", 5, record{Delta: 3}, codes.OK, "", ""},
+		{"decrement to 0", 5, record{Delta: -5}, codes.OK, "", ""},
+		{"decrement below 0", 5, record{Delta: -6}, codes.OutOfRange, "NEGATIVE", "-6"},
+		{"decrement an already negative counter", -5, record{Delta: -1}, codes.OutOfRange, "NEGATIVE", "-1"},
+		{"increment a negative counter", -5, record{Delta: 1}, codes.OK, "", ""},
+		{"overflow", math.MaxInt64 - 1, record{Delta: 2}, codes.OutOfRange, "OVERFLOW", "2"},
+		{"underflow", math.MinInt64 + 1, record{Delta: -2}, codes.OutOfRange, "OVERFLOW", "-2"},
+		{"increment to the max", math.MaxInt64 - 1, record{Delta: 1}, codes.OK, "", ""},
+		{"set below 0", 5, record{Op: opCompareAndSet, Expected: 5, New: -1}, codes.OutOfRange, "NEGATIVE", "-6"},
+		{"set the max to the min", math.MaxInt64, record{Op: opCompareAndSet, Expected: math.MaxInt64, New: math.MinInt64},
+			codes.OutOfRange, "NEGATIVE", "-18446744073709551615"},
+		{"set to something else", 5, record{Op: opCompareAndSet, Expected: 4, New: 1}, codes.FailedPrecondition, "", ""},
+		{"wrong version", 5, record{Op: opIncrementIf, Delta: 1, Version: 2}, codes.FailedPrecondition, "", ""},
+	}
+	var s Server
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := s.check(&counter{value: tt.value}, tt.rec)
+			if code := status.Code(err); code != tt.code {
+				t.Fatalf("check = %v, want code %v", err, tt.code)
+			}
+			if tt.reason == "" {
+				return
+			}
+			info := errorInfo(err)
+			if info == nil {
+				t.Fatalf("check = %v, without ErrorInfo", err)
+			}
+			if info.Reason != tt.reason || info.Metadata["amount"] != tt.amount {
+				t.Errorf("reason %s, amount %s, want %s, %s", info.Reason, info.Metadata["amount"], tt.reason, tt.amount)
+			}
+		})
+	}
+}
+
+func TestCheckAllowsNegative(t *testing.T) {
+	setFlag(t, nonNegative, false)
+
+	var s Server
+	if err := s.check(&counter{value: 5}, record{Delta: -6}); err != nil {
+		t.Errorf("check = %v, want nil without -non-negative", err)
+	}
+}
+
+func TestValidateAmount(t *testing.T) {
+	setFlag(t, minAmount, -10)
+	setFlag(t, maxAmount, 10)
+
+	tests := []struct {
+		amount int64
+		ok     bool
+	}{
+		{0, true},
+		{10, true},
+		{-10, true},
+		{11, false},
+		{-11, false},
+		{math.MaxInt64, false},
+		{math.MinInt64, false},
+	}
+	for _, tt := range tests {
+		err := validateAmount("delta", tt.amount)
+		if (err == nil) != tt.ok {
+			t.Errorf("validateAmount(%d) = %v, want ok %v", tt.amount, err, tt.ok)
+		}
+		if err != nil && status.Code(err) != codes.InvalidArgument {
+			t.Errorf("validateAmount(%d) = %v, want code %v", tt.amount, err, codes.InvalidArgument)
+		}
+	}
+}
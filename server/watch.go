@@ -0,0 +1,226 @@
+package main
+
+import (
+	"flag"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	gRPC "github.com/PatrickMatthiesen/DSYS-gRPC-template/proto"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var watchBuffer = flag.Int("watch-buffer", 256, "Changes that can wait to be sent to a watcher, a watcher that falls further behind is dropped")
+var watchHistory = flag.Int("watch-history", 1000, "Changes the server remembers, so a watcher that reconnects can get the ones it missed")
+
+// watchHub sends every change to the counters to the clients watching them.
+// Changes are never waited on: a watcher that can't keep up is dropped, so it can't hold up the increments.
+type watchHub struct {
+	mutex    sync.Mutex
+	epoch    uint64               // made when the hub is first used and on every reset, so a seq from before can't be resumed from
+	seq      uint64               // the seq of the last change
+	history  []*gRPC.CounterEvent // the last changes, oldest first
+	watchers map[*watcher]bool
+	closing  chan struct{} // closed when the server shuts down, made by closed
+}
+
+type watcher struct {
+	prefix  string
+	events  chan *gRPC.CounterEvent
+	dropped chan struct{} // closed when the watcher falls too far behind
+}
+
+// publish gives ev the next seq and queues it for everyone watching its key.
+// It is called with the counter locked, so the changes to a counter are in order.
+func (h *watchHub) publish(ev *gRPC.CounterEvent) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	h.seq++
+	ev.Seq, ev.Epoch = h.seq, h.currentEpoch()
+	h.history = append(h.history, ev)
+	if len(h.history) > *watchHistory {
+		h.history = h.history[len(h.history)-*watchHistory:]
+	}
+
+	for w := range h.watchers {
+		if !strings.HasPrefix(ev.Key, w.prefix) {
+			continue
+		}
+		select {
+		case w.events <- ev:
+		default:
+			// the watcher is too far behind, it has to catch up from the history
+			delete(h.watchers, w)
+			close(w.dropped)
+		}
+	}
+}
+
+// currentEpoch returns the epoch, making it the first time. The caller must hold h.mutex.
+// It is the time the hub started, so it is new every time the server restarts.
+func (h *watchHub) currentEpoch() uint64 {
+	if h.epoch == 0 {
+		h.epoch = uint64(time.Now().UnixNano())
+	}
+	return h.epoch
+}
+
+// reset forgets the history and drops every watcher, for when the counters are replaced all at once.
+// It starts a new epoch, so a watcher that reconnects starts over instead of resuming.
+func (h *watchHub) reset() {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	h.epoch = max(uint64(time.Now().UnixNano()), h.currentEpoch()+1)
+	h.history = nil
+	for w := range h.watchers {
+		delete(h.watchers, w)
+		close(w.dropped)
+	}
+}
+
+// subscribe starts a watch of the counters with keys starting with prefix.
+// If afterSeq is from this epoch, and the changes after it are still in the history, they are returned in missed
+// and resumed is true. Otherwise the watcher has to start over from the current values, which are as of seq in epoch.
+// It returns a nil watcher if the hub is closed.
+func (h *watchHub) subscribe(prefix string, afterEpoch, afterSeq uint64) (w *watcher, missed []*gRPC.CounterEvent, resumed bool, epoch, seq uint64) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if h.isClosed() {
+		return nil, nil, false, 0, 0
+	}
+
+	// the history holds every change after oldest, so it can only resume from there.
+	// a seq from another epoch is from before the server restarted or was reset, or from another server.
+	epoch = h.currentEpoch()
+	oldest := h.seq - uint64(len(h.history))
+	if afterEpoch == epoch && afterSeq > 0 && afterSeq >= oldest && afterSeq <= h.seq {
+		resumed = true
+		for _, ev := range h.history[afterSeq-oldest:] {
+			if strings.HasPrefix(ev.Key, prefix) {
+				missed = append(missed, ev)
+			}
+		}
+	}
+
+	w = &watcher{prefix: prefix, events: make(chan *gRPC.CounterEvent, *watchBuffer), dropped: make(chan struct{})}
+	if h.watchers == nil {
+		h.watchers = make(map[*watcher]bool)
+	}
+	h.watchers[w] = true
+	return w, missed, resumed, epoch, h.seq
+}
+
+// unsubscribe stops the watch of w.
+func (h *watchHub) unsubscribe(w *watcher) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	delete(h.watchers, w)
+}
+
+// len returns the number of watchers.
+func (h *watchHub) len() int {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	return len(h.watchers)
+}
+
+// closed returns a channel that is closed when the hub is.
+func (h *watchHub) closed() <-chan struct{} {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	if h.closing == nil {
+		h.closing = make(chan struct{})
+	}
+	return h.closing
+}
+
+// isClosed reports whether close has been called. The caller must hold h.mutex.
+func (h *watchHub) isClosed() bool {
+	select {
+	case <-h.closing:
+		return true
+	default:
+		return false
+	}
+}
+
+// close makes every Watch call return, so the server doesn't wait for them when it shuts down.
+func (h *watchHub) close() {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	if h.isClosed() {
+		return
+	}
+	if h.closing == nil {
+		h.closing = make(chan struct{})
+	}
+	close(h.closing)
+}
+
+// publishChange tells the watchers that rec changed a counter from before to after, which is nil if it was deleted.
+func (s *Server) publishChange(rec record, before, after *gRPC.Counter) {
+	ev := &gRPC.CounterEvent{Key: rec.key(), ClientName: rec.Client, Kind: gRPC.CounterEvent_CHANGED}
+	if after == nil {
+		ev.Kind, ev.Delta, ev.Version = gRPC.CounterEvent_DELETED, -before.Value, before.Version
+	} else {
+		ev.Value, ev.Delta, ev.Version = after.Value, after.Value-before.Value, after.Version
+	}
+	s.watchers.publish(ev)
+}
+
+func (s *Server) Watch(req *gRPC.WatchRequest, stream gRPC.Template_WatchServer) error {
+	w, missed, resumed, epoch, seq := s.watchers.subscribe(req.GetPrefix(), req.GetEpoch(), req.GetAfterSeq())
+	if w == nil {
+		return status.Error(codes.Unavailable, "the server is shutting down")
+	}
+	defer s.watchers.unsubscribe(w)
+	slog.InfoContext(stream.Context(), "watch started", "client", req.GetClientName(), "prefix", req.GetPrefix(), "epoch", req.GetEpoch(), "after_seq", req.GetAfterSeq(), "resumed", resumed)
+
+	// the values can be a bit newer than seq, but the changes that made them come next
+	// with the values after them, so the watcher ends up with the right ones anyway
+	last := req.GetAfterSeq()
+	if !resumed {
+		for _, c := range s.counters.list(req.GetPrefix()) {
+			ev := &gRPC.CounterEvent{Epoch: epoch, Seq: seq, Key: c.Key, Value: c.Value, Version: c.Version, Kind: gRPC.CounterEvent_CURRENT}
+			if err := stream.Send(ev); err != nil {
+				return err
+			}
+		}
+		last = seq
+	}
+
+	// the events are shared with the other watchers, which is fine as the lamport time is stamped on a copy
+	send := func(ev *gRPC.CounterEvent) error {
+		if err := stream.Send(ev); err != nil {
+			return err
+		}
+		last = ev.Seq
+		return nil
+	}
+	for _, ev := range missed {
+		if err := send(ev); err != nil {
+			return err
+		}
+	}
+
+	for {
+		select {
+		case ev := <-w.events:
+			if err := send(ev); err != nil {
+				return err
+			}
+		case <-w.dropped:
+			slog.WarnContext(stream.Context(), "dropped a watcher that fell behind", "client", req.GetClientName(), "last_seq", last)
+			return status.Errorf(codes.ResourceExhausted, "the watch fell too far behind, watch again after seq %d", last)
+		case <-s.watchers.closed():
+			return status.Errorf(codes.Unavailable, "the server is shutting down, watch again after seq %d", last)
+		case <-stream.Context().Done():
+			return status.FromContextError(stream.Context().Err()).Err()
+		}
+	}
+}
@@ -0,0 +1,129 @@
+// Package tlsutil makes the gRPC transport credentials for running the servers and clients over TLS,
+// and lets a handler find out who called it when clients are authenticated by certificate (mutual TLS).
+//
+// The certificates can be made with the certgen command, see GenerateCA and GenerateCert.
+package tlsutil
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+)
+
+// ServerCredentials makes the credentials for a server with the certificate in certFile and keyFile.
+// If mtls is true, clients must send a certificate signed by the CA in caFile.
+func ServerCredentials(certFile, keyFile, caFile string, mtls bool) (credentials.TransportCredentials, error) {
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("tlsutil: loading the server certificate: %w", err)
+	}
+
+	config := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+	if mtls {
+		if caFile == "" {
+			return nil, errors.New("tlsutil: mutual TLS needs a CA to check the client certificates with")
+		}
+		pool, err := loadCA(caFile)
+		if err != nil {
+			return nil, err
+		}
+		config.ClientCAs = pool
+		config.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return credentials.NewTLS(config), nil
+}
+
+// ClientCredentials makes the credentials for connecting to a server.
+// The server certificate is checked with the CA in caFile, or the CAs of the system if caFile is empty.
+// The certificate in certFile and keyFile is sent to the server if they are set, which is needed for mutual TLS.
+// serverName overrides the name the server certificate is checked against, leave it empty to use the host that is dialed.
+func ClientCredentials(certFile, keyFile, caFile, serverName string) (credentials.TransportCredentials, error) {
+	config := &tls.Config{
+		ServerName: serverName,
+		MinVersion: tls.VersionTLS12,
+	}
+	if caFile != "" {
+		pool, err := loadCA(caFile)
+		if err != nil {
+			return nil, err
+		}
+		config.RootCAs = pool
+	}
+	if certFile != "" || keyFile != "" {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("tlsutil: loading the client certificate: %w", err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+	return credentials.NewTLS(config), nil
+}
+
+// PeerIdentity returns the name in the verified certificate of the caller, when the server runs with mutual TLS.
+// The name is the common name of the certificate, or the first DNS name if it doesn't have one.
+// ok is false if the caller didn't send a verified certificate.
+func PeerIdentity(ctx context.Context) (name string, ok bool) {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return "", false
+	}
+	info, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(info.State.VerifiedChains) == 0 || len(info.State.VerifiedChains[0]) == 0 {
+		return "", false
+	}
+
+	return identity(info.State.VerifiedChains[0][0])
+}
+
+// CertIdentity returns the name in the certificate in certFile, the same way PeerIdentity does,
+// so a server can tell when the caller uses the same certificate as itself.
+func CertIdentity(certFile string) (name string, ok bool, err error) {
+	data, err := os.ReadFile(certFile)
+	if err != nil {
+		return "", false, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return "", false, fmt.Errorf("tlsutil: no certificate in %s", certFile)
+	}
+	parsed, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return "", false, fmt.Errorf("tlsutil: parsing %s: %w", certFile, err)
+	}
+	name, ok = identity(parsed)
+	return name, ok, nil
+}
+
+// identity is the common name of cert, or its first DNS name if it doesn't have one.
+func identity(cert *x509.Certificate) (name string, ok bool) {
+	switch {
+	case cert.Subject.CommonName != "":
+		return cert.Subject.CommonName, true
+	case len(cert.DNSNames) > 0:
+		return cert.DNSNames[0], true
+	default:
+		return "", false
+	}
+}
+
+func loadCA(caFile string) (*x509.CertPool, error) {
+	data, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, fmt.Errorf("tlsutil: reading the CA: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("tlsutil: no certificates found in %s", caFile)
+	}
+	return pool, nil
+}
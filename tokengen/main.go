@@ -0,0 +1,33 @@
+// tokengen makes a bearer token for a client of a server running with "-auth-secret".
+//
+//	go run ./tokengen -secret mysecret -subject alice
+//
+// prints a token, which the client uses with "-token <token>".
+// The server then knows the client as "alice", whatever name the client sends.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/PatrickMatthiesen/DSYS-gRPC-template/auth"
+)
+
+var secret = flag.String("secret", "", "The secret the server runs with")
+var subject = flag.String("subject", "", "Who the token is for")
+var ttl = flag.Duration("ttl", 24*time.Hour, "How long the token is valid")
+
+func main() {
+	flag.Parse()
+	if *secret == "" || *subject == "" {
+		log.Fatal("both -secret and -subject are needed")
+	}
+
+	token, err := auth.NewToken([]byte(*secret), *subject, *ttl)
+	if err != nil {
+		log.Fatalf("Failed to make the token: %v", err)
+	}
+	fmt.Println(token)
+}
@@ -0,0 +1,465 @@
+// Package tokenring implements mutual exclusion with a token that is passed around a ring of nodes.
+// Only the node holding the token may be in its critical section, so only one node at a time is in it.
+//
+// Every node is set up with the address of the next node in the ring. The token carries the addresses of
+// all the nodes, which it learns as it goes around, so a node can skip the next one if it can't be reached.
+//
+// If nobody has seen the token for a while, it is taken to be lost, and a node makes a new one with a higher
+// generation. Tokens of an older generation are dropped when they show up again, and so are copies of the token,
+// which can happen when a pass fails after all, as every node remembers the round it saw the token in last.
+package tokenring
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"math/rand"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	gRPC "github.com/PatrickMatthiesen/DSYS-gRPC-template/proto"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// ErrStopped is returned by Enter when the node is stopped, or leaves the ring, while it waits.
+var ErrStopped = errors.New("tokenring: stopped")
+
+// ErrAlreadyWanted is returned by Enter when the node is already in, or waiting for, the critical section.
+var ErrAlreadyWanted = errors.New("tokenring: already in or waiting for the critical section")
+
+type Config struct {
+	Name string // used in the log, if Logger is nil
+	Addr string // the address the other nodes can reach this node at, it is also the id of the node
+	Next string // the address of the next node in the ring
+
+	Pause        time.Duration // how long a node that doesn't want the critical section keeps the token, so it doesn't spin around the ring (default 100ms)
+	TokenTimeout time.Duration // how long nobody may have seen the token before a new one is made (a random time between it and twice that)
+
+	RetryInterval time.Duration     // how long to wait before passing the token again to a node that didn't answer
+	Retries       int               // how many times to try to pass the token to a node, before it is skipped (default 3)
+	DialOptions   []grpc.DialOption // used when dialing the other nodes
+
+	Logger *slog.Logger // where the node logs to (nil = slog.Default(), with the name of the node)
+}
+
+type Node struct {
+	cfg    Config
+	logger *slog.Logger
+
+	mu       sync.Mutex
+	token    *gRPC.Token   // the token, while we hold it
+	inCS     bool          // we are in the critical section
+	passing  bool          // we are passing the token on
+	wanting  chan struct{} // closed when we get the token, while Enter waits for it
+	leaving  chan struct{} // closed when we have left the ring, while Leave waits for it
+	left     bool          // we have left the ring
+	lastSeen time.Time     // when we last had the token
+	timeout  time.Duration // how long after lastSeen the token is taken to be lost
+
+	// the newest token we have seen, tokens that aren't newer are dropped
+	generation int64
+	creator    string
+	round      int64
+	ring       []string // the ring and removed nodes from the last token we saw, for when we make a new one
+	removed    []string
+
+	clientsMu sync.Mutex
+	clients   map[string]gRPC.TokenRingClient
+
+	stopOnce sync.Once
+	stop     chan struct{}
+}
+
+func New(cfg Config) *Node {
+	if cfg.Pause == 0 {
+		cfg.Pause = 100 * time.Millisecond
+	}
+	if cfg.TokenTimeout == 0 {
+		cfg.TokenTimeout = 5 * time.Second
+	}
+	if cfg.RetryInterval == 0 {
+		cfg.RetryInterval = time.Second
+	}
+	if cfg.Retries == 0 {
+		cfg.Retries = 3
+	}
+	return &Node{
+		cfg:     cfg,
+		logger:  newLogger(cfg.Logger, cfg.Name),
+		ring:    []string{cfg.Addr},
+		clients: make(map[string]gRPC.TokenRingClient),
+		stop:    make(chan struct{}),
+	}
+}
+
+// Start starts watching for a lost token. At first nobody has a token,
+// so the node that times out first makes one.
+func (n *Node) Start() {
+	n.mu.Lock()
+	n.seen()
+	n.mu.Unlock()
+	go n.watchToken()
+}
+
+// Stop stops the node without leaving the ring, like if it crashed. Enter returns ErrStopped.
+func (n *Node) Stop() {
+	n.stopOnce.Do(func() { close(n.stop) })
+}
+
+// Enter waits until the node holds the token, and so may enter the critical section. Call Exit when done with it.
+// If ctx is done first, ctx.Err() is returned.
+func (n *Node) Enter(ctx context.Context) error {
+	n.mu.Lock()
+	if n.inCS || n.wanting != nil {
+		n.mu.Unlock()
+		return ErrAlreadyWanted
+	}
+	if n.left {
+		n.mu.Unlock()
+		return ErrStopped
+	}
+	if n.token != nil && n.leaving == nil {
+		// we are holding on to it for a moment anyway
+		n.inCS = true
+		n.mu.Unlock()
+		return nil
+	}
+	wanting := make(chan struct{})
+	n.wanting = wanting
+	n.mu.Unlock()
+
+	select {
+	case <-wanting:
+		return nil
+	case <-ctx.Done():
+	case <-n.stop:
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	select {
+	case <-wanting:
+		// we got it just now, so we have to pass it on
+		n.passOn()
+	default:
+		n.wanting = nil
+	}
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return ErrStopped
+}
+
+// Exit leaves the critical section, and passes the token on.
+func (n *Node) Exit() {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if !n.inCS {
+		return
+	}
+	n.inCS = false
+	if n.leaving != nil {
+		n.leave()
+		return
+	}
+	n.passOn()
+}
+
+// Leave takes the node out of the ring. It waits for the token, so the ring knows about it when the token goes on,
+// and then stops the node. If ctx is done first, the node is stopped anyway, and the others find out it is gone
+// when they can't pass the token to it.
+func (n *Node) Leave(ctx context.Context) error {
+	defer n.Stop()
+
+	n.mu.Lock()
+	if n.left || n.leaving != nil {
+		n.mu.Unlock()
+		return nil
+	}
+	leaving := make(chan struct{})
+	n.leaving = leaving
+	if n.token != nil && !n.inCS && !n.passing {
+		n.leave()
+	}
+	n.mu.Unlock()
+
+	select {
+	case <-leaving:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-n.stop:
+		return ErrStopped
+	}
+}
+
+// leave passes the token on without us in the ring. The caller must hold n.mu and the token.
+func (n *Node) leave() {
+	t := n.token
+	n.token = nil
+	n.left = true
+	n.logger.Info("leaving the ring", "generation", t.Generation, "round", t.Round)
+
+	next := after(t.Ring, n.cfg.Addr)
+	t.Ring = remove(t.Ring, n.cfg.Addr)
+	t.Removed = append(t.Removed, n.cfg.Addr)
+	leaving := n.leaving
+	go func() {
+		if next != n.cfg.Addr { // if we were alone, the token leaves with us
+			n.pass(t, next)
+		}
+		close(leaving)
+	}()
+}
+
+// accept takes the token, if it is newer than the last one we saw. The caller must hold n.mu.
+func (n *Node) accept(t *gRPC.Token) {
+	// the first node in the ring counts the rounds. If it is taken out of the ring,
+	// the one after it is the first one, so the round is still counted once the token gets there.
+	if len(t.Ring) > 0 && t.Ring[0] == n.cfg.Addr {
+		t.Round++
+	}
+	if !n.newer(t) {
+		n.logger.Debug("dropping old token", "generation", t.Generation, "creator", t.Creator, "round", t.Round, "from", t.From)
+		return
+	}
+	if t.Generation != n.generation || t.Creator != n.creator {
+		n.logger.Info("got a new generation of the token", "generation", t.Generation, "creator", t.Creator)
+	}
+	n.generation, n.creator, n.round = t.Generation, t.Creator, t.Round
+
+	// the token learns the ring as it goes around: we go after the one that passed it to us,
+	// and the next node after us, unless it is known to have left
+	if !slices.Contains(t.Ring, n.cfg.Addr) {
+		t.Removed = remove(t.Removed, n.cfg.Addr) // we were thought to be gone, but we aren't
+		t.Ring = insertAfter(t.Ring, t.From, n.cfg.Addr)
+	}
+	if n.cfg.Next != "" && !slices.Contains(t.Ring, n.cfg.Next) && !slices.Contains(t.Removed, n.cfg.Next) {
+		t.Ring = insertAfter(t.Ring, n.cfg.Addr, n.cfg.Next)
+	}
+	n.ring = slices.Clone(t.Ring)
+	n.removed = slices.Clone(t.Removed)
+
+	n.token = t
+	n.seen()
+	switch {
+	case n.leaving != nil:
+		n.leave()
+	case n.wanting != nil:
+		n.inCS = true
+		close(n.wanting)
+		n.wanting = nil
+	default:
+		time.AfterFunc(n.cfg.Pause, func() {
+			n.mu.Lock()
+			defer n.mu.Unlock()
+			if n.token == t && !n.inCS {
+				n.passOn()
+			}
+		})
+	}
+}
+
+// newer reports whether t is newer than the last token we saw. The caller must hold n.mu.
+func (n *Node) newer(t *gRPC.Token) bool {
+	if t.Generation != n.generation {
+		return t.Generation > n.generation
+	}
+	if t.Creator != n.creator {
+		return t.Creator > n.creator
+	}
+	return t.Round > n.round
+}
+
+// passOn passes the token we hold to the next node in the ring, in the background. The caller must hold n.mu.
+func (n *Node) passOn() {
+	t := n.token
+	n.token = nil
+	n.passing = true
+	go func() {
+		n.pass(t, after(t.Ring, n.cfg.Addr))
+		n.mu.Lock()
+		n.passing = false
+		n.seen()
+		n.mu.Unlock()
+	}()
+}
+
+// pass passes t to next. A node that can't be reached after a few tries is taken out of the ring,
+// and the one after it is tried instead. If there is nobody else left, we get the token back ourselves.
+func (n *Node) pass(t *gRPC.Token, next string) {
+	t = proto.Clone(t).(*gRPC.Token) // it is changed while being sent, so it can't be shared
+	t.From = n.cfg.Addr
+	for {
+		if next == n.cfg.Addr {
+			n.mu.Lock()
+			n.accept(t)
+			n.mu.Unlock()
+			return
+		}
+		if n.send(next, t) {
+			return
+		}
+		select {
+		case <-n.stop:
+			return
+		default:
+		}
+
+		n.logger.Warn("skipping a node that can't be reached", "node", next)
+		skipped := next
+		next = after(t.Ring, skipped)
+		t.Ring = remove(t.Ring, skipped)
+		t.Removed = append(t.Removed, skipped)
+		if len(t.Ring) == 0 {
+			return // we are leaving, and the rest are gone
+		}
+	}
+}
+
+// send sends t to peer, and reports whether it got there. It gives up after cfg.Retries tries,
+// or right away if peer has left the ring.
+func (n *Node) send(peer string, t *gRPC.Token) bool {
+	for try := 1; ; try++ {
+		ctx, cancel := context.WithTimeout(context.Background(), n.cfg.RetryInterval)
+		_, err := n.client(peer).PassToken(ctx, t)
+		cancel()
+		if err == nil {
+			return true
+		}
+		n.logger.Debug("failed to pass the token", "peer", peer, "try", try, "err", err)
+		if status.Code(err) == codes.FailedPrecondition || try == n.cfg.Retries {
+			return false
+		}
+
+		select {
+		case <-n.stop:
+			return false
+		case <-time.After(n.cfg.RetryInterval):
+		}
+	}
+}
+
+// seen notes that we have just had the token, and picks a new random timeout,
+// so the nodes don't all make a new token at once. The caller must hold n.mu.
+func (n *Node) seen() {
+	n.lastSeen = time.Now()
+	n.timeout = n.cfg.TokenTimeout + time.Duration(rand.Int63n(int64(n.cfg.TokenTimeout)))
+}
+
+// watchToken makes a new token if nobody has seen it for a while.
+func (n *Node) watchToken() {
+	ticker := time.NewTicker(n.cfg.TokenTimeout / 10)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-n.stop:
+			return
+		case <-ticker.C:
+		}
+
+		n.mu.Lock()
+		if n.token == nil && !n.passing && !n.left && time.Since(n.lastSeen) > n.timeout {
+			t := &gRPC.Token{
+				Generation: n.generation + 1,
+				Creator:    n.cfg.Addr,
+				From:       n.cfg.Addr,
+				Ring:       slices.Clone(n.ring),
+				Removed:    slices.Clone(n.removed),
+			}
+			n.logger.Warn("the token is lost, making a new one", "generation", t.Generation, "last_seen", n.lastSeen.Format(time.TimeOnly))
+			n.accept(t)
+		}
+		n.mu.Unlock()
+	}
+}
+
+// server handles the TokenRing RPCs for a node.
+type server struct {
+	gRPC.UnimplementedTokenRingServer
+	n *Node
+}
+
+// Register registers the node's TokenRing service on s.
+func (n *Node) Register(s *grpc.Server) {
+	gRPC.RegisterTokenRingServer(s, &server{n: n})
+}
+
+// PassToken takes the token from the node before us in the ring.
+func (s *server) PassToken(ctx context.Context, t *gRPC.Token) (*gRPC.TokenAck, error) {
+	n := s.n
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	// the one passing it takes us out of the ring, and tries the next one
+	if n.left {
+		return nil, status.Error(codes.FailedPrecondition, "left the ring")
+	}
+	select {
+	case <-n.stop:
+		return nil, status.Error(codes.Unavailable, "stopped")
+	default:
+	}
+
+	n.accept(proto.Clone(t).(*gRPC.Token))
+	return &gRPC.TokenAck{}, nil
+}
+
+// after returns the node after addr in ring, going back to the start after the last one.
+// If addr isn't in the ring, it returns the first one.
+func after(ring []string, addr string) string {
+	i := slices.Index(ring, addr)
+	return ring[(i+1)%len(ring)]
+}
+
+// insertAfter returns ring with addr inserted after prev, or at the end if prev isn't in it.
+func insertAfter(ring []string, prev, addr string) []string {
+	i := slices.Index(ring, prev)
+	if i < 0 {
+		return append(ring, addr)
+	}
+	return slices.Insert(ring, i+1, addr)
+}
+
+// remove returns ring without addr.
+func remove(ring []string, addr string) []string {
+	return slices.DeleteFunc(ring, func(a string) bool { return a == addr })
+}
+
+// client returns a client for peer, dialing it the first time.
+func (n *Node) client(peer string) gRPC.TokenRingClient {
+	n.clientsMu.Lock()
+	defer n.clientsMu.Unlock()
+
+	if c, ok := n.clients[peer]; ok {
+		return c
+	}
+	// without grpc.WithBlock this doesn't wait for the connection,
+	// so it only fails if the options are wrong
+	conn, err := grpc.Dial(peer, n.cfg.DialOptions...)
+	if err != nil {
+		n.fatal("failed to dial peer", "peer", peer, "err", err)
+	}
+	c := gRPC.NewTokenRingClient(conn)
+	n.clients[peer] = c
+	return c
+}
+
+func newLogger(logger *slog.Logger, name string) *slog.Logger {
+	if logger == nil {
+		logger = slog.Default().With("node", name)
+	}
+	return logger.With("component", "tokenring")
+}
+
+// fatal logs msg and stops the program.
+func (n *Node) fatal(msg string, args ...any) {
+	n.logger.Error(msg, args...)
+	os.Exit(1)
+}
@@ -0,0 +1,46 @@
+package tracing
+
+import (
+	"encoding/json"
+	"log/slog"
+	"os"
+	"sync"
+)
+
+// FileExporter writes every span as a line of JSON to a file, so traces can be looked at
+// without running a collector. Give the client and the servers the same file to get
+// all the spans of a trace in one place:
+//
+//	jq -c 'select(.traceId == "4bf92f3577b34da6a3ce929d0e0e4736")' traces.json
+type FileExporter struct {
+	mu   sync.Mutex
+	f    *os.File
+	enc  *json.Encoder
+	path string
+}
+
+// NewFileExporter opens path for appending, and makes it if it doesn't exist.
+func NewFileExporter(path string) (*FileExporter, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	return &FileExporter{f: f, enc: json.NewEncoder(f), path: path}, nil
+}
+
+// Export writes span to the file. Every span is written in a single write,
+// so more processes can append to the same file.
+func (e *FileExporter) Export(span SpanData) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if err := e.enc.Encode(span); err != nil {
+		slog.Error("tracing: failed to write span", "file", e.path, "err", err)
+	}
+}
+
+// Close closes the file, spans exported after this are lost.
+func (e *FileExporter) Close() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.f.Close()
+}
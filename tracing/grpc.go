@@ -0,0 +1,175 @@
+package tracing
+
+import (
+	"context"
+	"io"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// the metadata key the span context is sent in
+const traceparentKey = "traceparent"
+
+// The interceptors below make a span for every call: a client span on the side that makes it,
+// and a server span, which is a child of the client span, on the side that handles it.
+// Every message sent or received on a stream gets a span too, as a child of the span of the stream.
+
+// spanName turns "/proto.Template/Increment" into "proto.Template/Increment".
+func spanName(fullMethod string) string {
+	return strings.TrimPrefix(fullMethod, "/")
+}
+
+// endWith sets the status of span from err and ends it.
+func endWith(span *Span, err error) {
+	if err != nil {
+		span.SetAttribute("rpc.grpc.status_code", status.Code(err).String())
+	}
+	span.SetError(err)
+	span.End()
+}
+
+// startServer starts the server span of a call, as a child of the traceparent the client sent, if any.
+func (t *Tracer) startServer(ctx context.Context, fullMethod string) (context.Context, *Span) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if values := md.Get(traceparentKey); len(values) > 0 {
+		if sc, err := ParseTraceparent(values[0]); err == nil {
+			ctx = ContextWithRemote(ctx, sc)
+		}
+	}
+	ctx, span := t.Start(ctx, spanName(fullMethod), KindServer)
+	if p, ok := peer.FromContext(ctx); ok {
+		span.SetAttribute("net.peer.addr", p.Addr.String())
+	}
+	return ctx, span
+}
+
+// startClient starts the client span of a call, and puts it in the metadata so the server can continue the trace.
+// With tracing off there is no span, and nothing is sent, as a traceparent of zeroes isn't valid.
+func (t *Tracer) startClient(ctx context.Context, method string) (context.Context, *Span) {
+	ctx, span := t.Start(ctx, spanName(method), KindClient)
+	if sc := span.SpanContext(); sc.IsValid() {
+		ctx = metadata.AppendToOutgoingContext(ctx, traceparentKey, sc.Traceparent())
+	}
+	return ctx, span
+}
+
+// UnaryServerInterceptor makes a server span for every call.
+func UnaryServerInterceptor(t *Tracer) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, span := t.startServer(ctx, info.FullMethod)
+		resp, err := handler(ctx, req)
+		endWith(span, err)
+		return resp, err
+	}
+}
+
+// StreamServerInterceptor makes a server span for every stream, and a span for every message on it.
+func StreamServerInterceptor(t *Tracer) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, span := t.startServer(ss.Context(), info.FullMethod)
+		err := handler(srv, &serverStream{ServerStream: ss, ctx: ctx, t: t, name: spanName(info.FullMethod)})
+		endWith(span, err)
+		return err
+	}
+}
+
+type serverStream struct {
+	grpc.ServerStream
+	ctx  context.Context
+	t    *Tracer
+	name string
+	sent int
+	recv int
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *serverStream) SendMsg(m any) error {
+	s.sent++
+	return messageSpan(s.ctx, s.t, s.name, "send", s.sent, func() error { return s.ServerStream.SendMsg(m) })
+}
+
+func (s *serverStream) RecvMsg(m any) error {
+	s.recv++
+	return messageSpan(s.ctx, s.t, s.name, "recv", s.recv, func() error { return s.ServerStream.RecvMsg(m) })
+}
+
+// messageSpan makes a span around sending or receiving a single message on a stream.
+// The end of the stream (io.EOF) isn't an error, and doesn't get a span.
+func messageSpan(ctx context.Context, t *Tracer, name, direction string, n int, do func() error) error {
+	_, span := t.Start(ctx, name+" "+direction, KindInternal)
+	err := do()
+	if err == io.EOF {
+		return err
+	}
+	span.SetAttribute("message.id", n)
+	endWith(span, err)
+	return err
+}
+
+// UnaryClientInterceptor makes a client span for every call.
+func UnaryClientInterceptor(t *Tracer) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		ctx, span := t.startClient(ctx, method)
+		span.SetAttribute("net.peer.target", cc.Target())
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		endWith(span, err)
+		return err
+	}
+}
+
+// StreamClientInterceptor makes a client span for every stream, and a span for every message on it.
+// The span of the stream ends when the stream fails or the last message has been received.
+func StreamClientInterceptor(t *Tracer) grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		ctx, span := t.startClient(ctx, method)
+		span.SetAttribute("net.peer.target", cc.Target())
+		cs, err := streamer(ctx, desc, cc, method, opts...)
+		if err != nil {
+			endWith(span, err)
+			return nil, err
+		}
+		return &clientStream{ClientStream: cs, ctx: ctx, t: t, span: span, name: spanName(method), serverStreams: desc.ServerStreams}, nil
+	}
+}
+
+type clientStream struct {
+	grpc.ClientStream
+	ctx           context.Context
+	t             *Tracer
+	span          *Span
+	name          string
+	serverStreams bool // the server sends more than one message, so the stream is done at io.EOF
+	sent          int
+	recv          int
+}
+
+func (s *clientStream) SendMsg(m any) error {
+	s.sent++
+	err := messageSpan(s.ctx, s.t, s.name, "send", s.sent, func() error { return s.ClientStream.SendMsg(m) })
+	if err != nil && err != io.EOF {
+		endWith(s.span, err)
+	}
+	return err
+}
+
+func (s *clientStream) RecvMsg(m any) error {
+	s.recv++
+	err := messageSpan(s.ctx, s.t, s.name, "recv", s.recv, func() error { return s.ClientStream.RecvMsg(m) })
+	switch {
+	case err == io.EOF:
+		endWith(s.span, nil)
+	case err != nil:
+		endWith(s.span, err)
+	case !s.serverStreams:
+		// the server only sends one message, so the stream is done when it has been received
+		endWith(s.span, nil)
+	}
+	return err
+}
@@ -0,0 +1,32 @@
+package tracing
+
+import (
+	"context"
+	"testing"
+
+	"google.golang.org/grpc/metadata"
+)
+
+func TestStartClientTraceparent(t *testing.T) {
+	// with tracing off there is no span, so nothing may be sent
+	ctx, _ := (*Tracer)(nil).startClient(context.Background(), "/proto.Template/Increment")
+	md, _ := metadata.FromOutgoingContext(ctx)
+	if values := md.Get(traceparentKey); len(values) > 0 {
+		t.Errorf("sent traceparent %q with tracing off", values)
+	}
+
+	tracer := NewTracer("test", nil)
+	ctx, span := tracer.startClient(context.Background(), "/proto.Template/Increment")
+	md, _ = metadata.FromOutgoingContext(ctx)
+	values := md.Get(traceparentKey)
+	if len(values) != 1 {
+		t.Fatalf("sent %d traceparents, want 1", len(values))
+	}
+	sc, err := ParseTraceparent(values[0])
+	if err != nil {
+		t.Fatalf("ParseTraceparent(%q): %v", values[0], err)
+	}
+	if sc != span.SpanContext() {
+		t.Errorf("sent %v, want the span %v", sc, span.SpanContext())
+	}
+}
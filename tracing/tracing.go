@@ -0,0 +1,245 @@
+// Package tracing follows a call from the client through every server it touches.
+// Every piece of work is a span, and the spans of one call share a trace id,
+// so the logs of the client and the servers can be put together afterwards.
+//
+// The trace id is passed between processes in the "traceparent" metadata in the
+// W3C Trace Context format used by OpenTelemetry, https://www.w3.org/TR/trace-context/:
+//
+//	traceparent: 00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01
+//	             version-trace id-parent span id-flags
+//
+// The finished spans are written to an Exporter, like a FileExporter.
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TraceID identifies a whole trace, which is all the spans of one call.
+type TraceID [16]byte
+
+// SpanID identifies a single span.
+type SpanID [8]byte
+
+func (t TraceID) String() string { return hex.EncodeToString(t[:]) }
+func (s SpanID) String() string  { return hex.EncodeToString(s[:]) }
+
+// IsValid reports whether t is set, as an id of only zeroes isn't allowed.
+func (t TraceID) IsValid() bool { return t != TraceID{} }
+
+// IsValid reports whether s is set, as an id of only zeroes isn't allowed.
+func (s SpanID) IsValid() bool { return s != SpanID{} }
+
+// SpanContext is what is passed on to the next process, so its spans become part of the same trace.
+type SpanContext struct {
+	TraceID TraceID
+	SpanID  SpanID
+}
+
+// IsValid reports whether both ids of sc are set. The span of a nil Tracer has neither.
+func (sc SpanContext) IsValid() bool { return sc.TraceID.IsValid() && sc.SpanID.IsValid() }
+
+// Traceparent formats sc as a traceparent header.
+func (sc SpanContext) Traceparent() string {
+	return fmt.Sprintf("00-%s-%s-01", sc.TraceID, sc.SpanID)
+}
+
+// ParseTraceparent reads a traceparent header made by Traceparent, or another W3C Trace Context implementation.
+func ParseTraceparent(header string) (SpanContext, error) {
+	var sc SpanContext
+	parts := strings.Split(strings.TrimSpace(header), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" {
+		return sc, fmt.Errorf("tracing: bad traceparent %q", header)
+	}
+	// versions after 00 may add more fields, but the first four stay the same
+	if parts[0] == "00" && len(parts) != 4 {
+		return sc, fmt.Errorf("tracing: bad traceparent %q", header)
+	}
+	if err := decodeHex(sc.TraceID[:], parts[1]); err != nil || !sc.TraceID.IsValid() {
+		return sc, fmt.Errorf("tracing: bad trace id in %q", header)
+	}
+	if err := decodeHex(sc.SpanID[:], parts[2]); err != nil || !sc.SpanID.IsValid() {
+		return sc, fmt.Errorf("tracing: bad span id in %q", header)
+	}
+	return sc, nil
+}
+
+func decodeHex(dst []byte, s string) error {
+	if len(s) != 2*len(dst) || strings.ToLower(s) != s {
+		return fmt.Errorf("tracing: bad hex %q", s)
+	}
+	_, err := hex.Decode(dst, []byte(s))
+	return err
+}
+
+// Kind tells what role a span plays in a call.
+type Kind string
+
+const (
+	KindInternal Kind = "internal" // work inside a process
+	KindServer   Kind = "server"   // handling a call from another process
+	KindClient   Kind = "client"   // calling another process
+)
+
+// Span is a piece of work with a start and an end. Make one with Tracer.Start,
+// and call End when the work is done. All the methods do nothing on a nil span,
+// so code can trace without checking if tracing is turned on.
+type Span struct {
+	tracer *Tracer
+	sc     SpanContext
+
+	mu   sync.Mutex
+	data SpanData
+	done bool
+}
+
+// SpanData is a finished span, as it is given to the exporter.
+type SpanData struct {
+	TraceID    string            `json:"traceId"`
+	SpanID     string            `json:"spanId"`
+	ParentID   string            `json:"parentSpanId,omitempty"`
+	Name       string            `json:"name"`
+	Kind       Kind              `json:"kind"`
+	Service    string            `json:"service"`
+	Start      time.Time         `json:"start"`
+	End        time.Time         `json:"end"`
+	Duration   float64           `json:"durationMs"`
+	Attributes map[string]string `json:"attributes,omitempty"`
+	Status     string            `json:"status"` // "OK", or the error
+}
+
+// SpanContext returns what is needed to make spans in other processes part of the same trace.
+func (s *Span) SpanContext() SpanContext {
+	if s == nil {
+		return SpanContext{}
+	}
+	return s.sc
+}
+
+// TraceID returns the id of the trace the span is part of, which is handy to put in logs.
+func (s *Span) TraceID() string {
+	if s == nil {
+		return ""
+	}
+	return s.sc.TraceID.String()
+}
+
+// SetAttribute adds a key and value to the span, like the name of the client.
+func (s *Span) SetAttribute(key string, value any) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.data.Attributes == nil {
+		s.data.Attributes = make(map[string]string)
+	}
+	s.data.Attributes[key] = fmt.Sprint(value)
+}
+
+// SetError marks the span as failed with err, or as OK if err is nil.
+func (s *Span) SetError(err error) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err == nil {
+		s.data.Status = "OK"
+	} else {
+		s.data.Status = err.Error()
+	}
+}
+
+// End finishes the span and sends it to the exporter. Calling it again does nothing.
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	if s.done {
+		s.mu.Unlock()
+		return
+	}
+	s.done = true
+	s.data.End = time.Now()
+	s.data.Duration = float64(s.data.End.Sub(s.data.Start)) / float64(time.Millisecond)
+	data := s.data
+	s.mu.Unlock()
+
+	s.tracer.exporter.Export(data)
+}
+
+// Exporter gets every finished span.
+type Exporter interface {
+	Export(span SpanData)
+}
+
+// Tracer makes spans for a service. All the methods work on a nil tracer, and then don't trace anything.
+type Tracer struct {
+	service  string
+	exporter Exporter
+}
+
+// NewTracer makes a tracer that names its spans as coming from service, and sends them to exporter.
+func NewTracer(service string, exporter Exporter) *Tracer {
+	return &Tracer{service: service, exporter: exporter}
+}
+
+type spanKey struct{}
+type remoteKey struct{}
+
+// SpanFromContext returns the span in ctx, or nil if there isn't one.
+func SpanFromContext(ctx context.Context) *Span {
+	s, _ := ctx.Value(spanKey{}).(*Span)
+	return s
+}
+
+// ContextWithRemote returns ctx with a span context from another process,
+// which the next span started from ctx becomes a child of.
+func ContextWithRemote(ctx context.Context, sc SpanContext) context.Context {
+	return context.WithValue(ctx, remoteKey{}, sc)
+}
+
+// Start starts a span called name. It is a child of the span in ctx, or of the remote
+// span from ContextWithRemote, or else the first span of a new trace.
+// The returned context holds the new span.
+func (t *Tracer) Start(ctx context.Context, name string, kind Kind) (context.Context, *Span) {
+	if t == nil {
+		return ctx, nil
+	}
+
+	var parent SpanContext
+	if p := SpanFromContext(ctx); p != nil {
+		parent = p.SpanContext()
+	} else if remote, ok := ctx.Value(remoteKey{}).(SpanContext); ok {
+		parent = remote
+	}
+
+	traceID := parent.TraceID
+	if !traceID.IsValid() {
+		rand.Read(traceID[:])
+	}
+	var spanID SpanID
+	rand.Read(spanID[:])
+
+	s := &Span{tracer: t, sc: SpanContext{TraceID: traceID, SpanID: spanID}, data: SpanData{
+		TraceID: traceID.String(),
+		SpanID:  spanID.String(),
+		Name:    name,
+		Kind:    kind,
+		Service: t.service,
+		Start:   time.Now(),
+		Status:  "OK",
+	}}
+	if parent.SpanID.IsValid() {
+		s.data.ParentID = parent.SpanID.String()
+	}
+	return context.WithValue(ctx, spanKey{}, s), s
+}
@@ -0,0 +1,121 @@
+package vclock
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Clock is the vector clock of a single node. It is safe for concurrent use.
+type Clock struct {
+	name string
+
+	mu      sync.Mutex
+	v       VClock
+	changed chan struct{} // closed and replaced every time v changes, to wake up waiting deliveries
+}
+
+// NewClock returns a clock for the node called name, with every counter at 0.
+func NewClock(name string) *Clock {
+	return &Clock{name: name, v: make(VClock), changed: make(chan struct{})}
+}
+
+// Name returns the name of the node the clock belongs to.
+func (c *Clock) Name() string {
+	return c.name
+}
+
+// Now returns a copy of the current vector.
+func (c *Clock) Now() VClock {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.v.Copy()
+}
+
+// Send counts a message sent by this node and returns the vector to send along with it.
+func (c *Clock) Send() VClock {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.v[c.name]++
+	c.notify()
+	return c.v.Copy()
+}
+
+// Merge merges the vector of a message into the clock, without checking the causal order.
+// Use it for replies to our own messages, they can't be out of order.
+func (c *Clock) Merge(m VClock) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.v.Merge(m)
+	c.notify()
+}
+
+// Deliver merges the vector m of a message from the node called from into the clock.
+//
+// If the message depends on messages from other nodes that haven't been delivered here, it arrived out of
+// causal order, which is logged. With wait > 0 the delivery is then held back until those messages have been
+// delivered (causal delivery), or until wait has passed or ctx is done, whichever comes first. It returns false
+// if the message was delivered out of causal order, either because wait was 0 or because the messages never came.
+func (c *Clock) Deliver(ctx context.Context, from string, m VClock, wait time.Duration) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if from == c.name {
+		slog.Warn("vclock: got a message with our own name, give the nodes different names", "from", from)
+	}
+	if c.seen(from, m) {
+		c.v.Merge(m)
+		c.notify()
+		return true
+	}
+
+	slog.InfoContext(ctx, "vclock: message arrived out of causal order", "from", from, "vclock", m, "local", c.v)
+	if wait > 0 {
+		timer := time.NewTimer(wait)
+		defer timer.Stop()
+	waiting:
+		for !c.seen(from, m) {
+			changed := c.changed
+			c.mu.Unlock()
+			select {
+			case <-changed:
+				c.mu.Lock()
+			case <-timer.C:
+				c.mu.Lock()
+				break waiting
+			case <-ctx.Done():
+				c.mu.Lock()
+				break waiting
+			}
+		}
+	}
+
+	inOrder := c.seen(from, m)
+	if inOrder {
+		slog.InfoContext(ctx, "vclock: delivering message after the messages it depends on", "from", from, "vclock", m)
+	} else {
+		slog.InfoContext(ctx, "vclock: delivering message out of causal order", "from", from, "vclock", m)
+	}
+	c.v.Merge(m)
+	c.notify()
+	return inOrder
+}
+
+// seen reports whether we have delivered every message from the other nodes that m depends on.
+// The counter of the sender itself isn't checked, as it counts the messages it sent to every node, not only to us.
+// The caller must hold c.mu.
+func (c *Clock) seen(from string, m VClock) bool {
+	for node, t := range m {
+		if node != from && t > c.v[node] {
+			return false
+		}
+	}
+	return true
+}
+
+// notify wakes up deliveries waiting for the clock to change. The caller must hold c.mu.
+func (c *Clock) notify() {
+	close(c.changed)
+	c.changed = make(chan struct{})
+}
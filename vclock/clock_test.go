@@ -0,0 +1,59 @@
+package vclock
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestDeliverInOrder(t *testing.T) {
+	c := NewClock("server")
+	if !c.Deliver(context.Background(), "alice", VClock{"alice": 3}, 0) {
+		t.Error("Deliver = false for a message without dependencies")
+	}
+	if got := c.Now()["alice"]; got != 3 {
+		t.Errorf("alice = %d after Deliver, want 3", got)
+	}
+}
+
+func TestDeliverOutOfOrder(t *testing.T) {
+	c := NewClock("server")
+	start := time.Now()
+	if c.Deliver(context.Background(), "bob", VClock{"alice": 1, "bob": 1}, 0) {
+		t.Error("Deliver = true for a message that depends on one we haven't seen")
+	}
+	if time.Since(start) > 100*time.Millisecond {
+		t.Error("Deliver waited without a wait")
+	}
+	if got := c.Now(); got["alice"] != 1 || got["bob"] != 1 {
+		t.Errorf("clock = %v after Deliver, want the message merged anyway", got)
+	}
+}
+
+func TestDeliverWaits(t *testing.T) {
+	c := NewClock("server")
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		c.Deliver(context.Background(), "alice", VClock{"alice": 1}, 0)
+	}()
+	if !c.Deliver(context.Background(), "bob", VClock{"alice": 1, "bob": 1}, 5*time.Second) {
+		t.Error("Deliver = false, want it to wait for the message it depends on")
+	}
+}
+
+func TestDeliverWaitIsBounded(t *testing.T) {
+	c := NewClock("server")
+	start := time.Now()
+	if c.Deliver(context.Background(), "bob", VClock{"alice": 1, "bob": 1}, 20*time.Millisecond) {
+		t.Error("Deliver = true, but the message it depends on never came")
+	}
+	if waited := time.Since(start); waited < 20*time.Millisecond || waited > time.Second {
+		t.Errorf("Deliver waited %v, want about 20ms", waited)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if c.Deliver(ctx, "carol", VClock{"dave": 1, "carol": 1}, time.Hour) {
+		t.Error("Deliver = true after the call was cancelled")
+	}
+}
@@ -0,0 +1,138 @@
+package vclock
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// The vector clock travels in the metadata of a call (like an HTTP header),
+// together with the name of the node that sent it.
+// A stream counts as a single message, sent when it is opened.
+const (
+	clockKey = "vclock"
+	nodeKey  = "vclock-node"
+)
+
+type contextKey struct{}
+
+// FromContext returns the vector clock of the message a server is handling,
+// if the caller sent one.
+func FromContext(ctx context.Context) (VClock, bool) {
+	v, ok := ctx.Value(contextKey{}).(VClock)
+	return v, ok
+}
+
+// deliverIncoming delivers the vector clock in the incoming metadata, if there is one,
+// and returns a context that FromContext can get it from.
+func deliverIncoming(ctx context.Context, c *Clock, wait time.Duration) (context.Context, bool) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	clocks, nodes := md.Get(clockKey), md.Get(nodeKey)
+	if len(clocks) == 0 || len(nodes) == 0 {
+		return ctx, false // the caller doesn't use vector clocks
+	}
+	m, err := Parse(clocks[0])
+	if err != nil {
+		return ctx, false
+	}
+	c.Deliver(ctx, nodes[0], m, wait)
+	return context.WithValue(ctx, contextKey{}, m), true
+}
+
+// outgoing returns the metadata to send along with a message from c.
+func outgoing(c *Clock) metadata.MD {
+	return metadata.Pairs(clockKey, c.Send().String(), nodeKey, c.Name())
+}
+
+// mergeHeader merges the vector clock in the header of a response.
+func mergeHeader(c *Clock, header metadata.MD) {
+	if clocks := header.Get(clockKey); len(clocks) > 0 {
+		if m, err := Parse(clocks[0]); err == nil {
+			c.Merge(m)
+		}
+	}
+}
+
+// UnaryServerInterceptor delivers the vector clock of every request that has one,
+// holding it back for up to wait if it arrived out of causal order (0 = don't hold it back),
+// and sends the server's clock back in the response header.
+func UnaryServerInterceptor(c *Clock, wait time.Duration) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, ok := deliverIncoming(ctx, c, wait)
+		resp, err := handler(ctx, req)
+		if ok {
+			grpc.SetHeader(ctx, outgoing(c))
+		}
+		return resp, err
+	}
+}
+
+// StreamServerInterceptor delivers the vector clock a stream was opened with, like UnaryServerInterceptor,
+// and sends the server's clock back in the header right away.
+func StreamServerInterceptor(c *Clock, wait time.Duration) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, ok := deliverIncoming(ss.Context(), c, wait)
+		if ok {
+			ss.SetHeader(outgoing(c))
+		}
+		return handler(srv, &serverStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
+
+// UnaryClientInterceptor sends the client's vector clock with every call and merges the one in the response.
+func UnaryClientInterceptor(c *Clock) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		ctx = metadata.NewOutgoingContext(ctx, metadata.Join(outgoingMD(ctx), outgoing(c)))
+		var header metadata.MD
+		err := invoker(ctx, method, req, reply, cc, append(opts, grpc.Header(&header))...)
+		mergeHeader(c, header)
+		return err
+	}
+}
+
+// StreamClientInterceptor sends the client's vector clock when a stream is opened,
+// and merges the one in the header the server sends back.
+func StreamClientInterceptor(c *Clock) grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		ctx = metadata.NewOutgoingContext(ctx, metadata.Join(outgoingMD(ctx), outgoing(c)))
+		cs, err := streamer(ctx, desc, cc, method, opts...)
+		if err != nil {
+			return nil, err
+		}
+		return &clientStream{ClientStream: cs, c: c}, nil
+	}
+}
+
+type clientStream struct {
+	grpc.ClientStream
+	c      *Clock
+	merged bool
+}
+
+// the header is there once the first message has been received
+func (s *clientStream) RecvMsg(m any) error {
+	err := s.ClientStream.RecvMsg(m)
+	if !s.merged {
+		if header, herr := s.ClientStream.Header(); herr == nil {
+			mergeHeader(s.c, header)
+			s.merged = true
+		}
+	}
+	return err
+}
+
+func outgoingMD(ctx context.Context) metadata.MD {
+	md, _ := metadata.FromOutgoingContext(ctx)
+	return md
+}
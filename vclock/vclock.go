@@ -0,0 +1,129 @@
+// Package vclock implements vector clocks, which unlike Lamport clocks can tell
+// whether two events are causally related or happened concurrently.
+//
+// A vector clock holds a counter for every node, keyed by the node's name.
+// Here a node's counter is the number of messages it has sent, so:
+//
+//   - before sending a message, a node increments its own counter and sends its vector along,
+//   - when a message is delivered, the receiver merges the message's vector into its own.
+//
+// A message that depends on messages from other nodes the receiver hasn't delivered yet arrived out of causal order.
+// Deliver logs it, and can hold it back for a while until those messages have been delivered (causal delivery).
+// The wait is bounded, as a message it depends on may have gone to another node and never come.
+package vclock
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// VClock maps node names to the number of messages they have sent. A missing node counts as 0.
+type VClock map[string]uint64
+
+// Ordering is how two vector clocks relate to each other.
+type Ordering int
+
+const (
+	Equal      Ordering = iota
+	Before              // the first happened before the second
+	After               // the first happened after the second
+	Concurrent          // neither happened before the other
+)
+
+func (o Ordering) String() string {
+	return [...]string{"equal", "before", "after", "concurrent"}[o]
+}
+
+// Compare returns how a relates to b.
+func Compare(a, b VClock) Ordering {
+	less, greater := false, false
+	for node, t := range a {
+		if t > b[node] {
+			greater = true
+		} else if t < b[node] {
+			less = true
+		}
+	}
+	for node, t := range b {
+		if _, ok := a[node]; !ok && t > 0 {
+			less = true
+		}
+	}
+
+	switch {
+	case less && greater:
+		return Concurrent
+	case less:
+		return Before
+	case greater:
+		return After
+	default:
+		return Equal
+	}
+}
+
+// HappenedBefore reports whether the event with clock v happened before the one with clock o.
+func (v VClock) HappenedBefore(o VClock) bool {
+	return Compare(v, o) == Before
+}
+
+// ConcurrentWith reports whether neither of v and o happened before the other.
+func (v VClock) ConcurrentWith(o VClock) bool {
+	return Compare(v, o) == Concurrent
+}
+
+// Copy returns a copy of v that can be changed without changing v.
+func (v VClock) Copy() VClock {
+	c := make(VClock, len(v))
+	for node, t := range v {
+		c[node] = t
+	}
+	return c
+}
+
+// Merge sets every counter in v to the highest of its own and the one in o.
+func (v VClock) Merge(o VClock) {
+	for node, t := range o {
+		if t > v[node] {
+			v[node] = t
+		}
+	}
+}
+
+// String encodes v as "name:counter" pairs sorted by name, ex. "alice:2,server:5".
+// Parse turns it back into a VClock.
+func (v VClock) String() string {
+	nodes := make([]string, 0, len(v))
+	for node := range v {
+		nodes = append(nodes, node)
+	}
+	sort.Strings(nodes)
+
+	pairs := make([]string, len(nodes))
+	for i, node := range nodes {
+		pairs[i] = node + ":" + strconv.FormatUint(v[node], 10)
+	}
+	return strings.Join(pairs, ",")
+}
+
+// Parse decodes a vector clock encoded by String.
+func Parse(s string) (VClock, error) {
+	v := make(VClock)
+	if s == "" {
+		return v, nil
+	}
+	for _, pair := range strings.Split(s, ",") {
+		i := strings.LastIndex(pair, ":")
+		if i < 0 {
+			return nil, fmt.Errorf("vclock: missing ':' in %q", pair)
+		}
+		t, err := strconv.ParseUint(pair[i+1:], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("vclock: bad counter in %q: %v", pair, err)
+		}
+		v[pair[:i]] = t
+	}
+	return v, nil
+}
@@ -0,0 +1,282 @@
+// Package wal is a small write-ahead log with snapshots.
+//
+// Every record is appended to a log file and fsync'd before Append returns,
+// so anything that was acknowledged survives a crash. To keep recovery fast
+// the owner can write a snapshot of its whole state once in a while, after
+// which the log is compacted (emptied).
+//
+// On disk a record looks like this:
+//
+//	| length uint32 | crc32 uint32 | index uint64 | payload (length bytes) |
+//
+// The checksum covers the index and the payload. When the log is opened, the
+// records are read until the first one that is incomplete or has a bad
+// checksum. Such a record can only be the result of a crash in the middle of a
+// write (a "torn" write), so the log is truncated at that point instead of
+// refusing to start.
+package wal
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+)
+
+const (
+	logName      = "wal.log"
+	snapshotName = "snapshot"
+	headerSize   = 16 // length + crc + index
+
+	// records larger than this are treated as garbage when reading the log.
+	maxRecordSize = 64 << 20
+)
+
+var crcTable = crc32.MakeTable(crc32.Castagnoli)
+
+// ErrCorruptSnapshot is returned by Open when the snapshot file fails its checksum.
+// Snapshots are written to a temporary file and renamed into place,
+// so unlike a torn log record this is never caused by a crash.
+var ErrCorruptSnapshot = errors.New("wal: snapshot is corrupt")
+
+// Log is an append-only log of records in a directory. It is safe for concurrent use.
+type Log struct {
+	mu    sync.Mutex
+	dir   string
+	file  *os.File
+	index uint64 // index of the last record appended (or covered by the snapshot)
+}
+
+// Open opens the log in dir, creating the directory if it doesn't exist.
+// It returns the latest snapshot (nil if there is none)
+// and the records that were appended after it, in order.
+func Open(dir string) (l *Log, snapshot []byte, records [][]byte, err error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, nil, nil, err
+	}
+
+	snapIndex, snapshot, err := readSnapshot(filepath.Join(dir, snapshotName))
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	file, err := os.OpenFile(filepath.Join(dir, logName), os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	l = &Log{dir: dir, file: file, index: snapIndex}
+	records, err = l.replay(snapIndex)
+	if err != nil {
+		file.Close()
+		return nil, nil, nil, err
+	}
+	return l, snapshot, records, nil
+}
+
+// replay reads every valid record in the log file, truncates a torn tail if
+// there is one, and leaves the file offset at the end of the log.
+func (l *Log) replay(snapIndex uint64) ([][]byte, error) {
+	var records [][]byte
+	var offset int64
+	header := make([]byte, headerSize)
+
+	for {
+		var payload []byte
+		_, err := io.ReadFull(l.file, header)
+		if err == nil {
+			length := binary.LittleEndian.Uint32(header[0:4])
+			if length > maxRecordSize {
+				err = fmt.Errorf("record length %d is too large", length)
+			} else {
+				payload = make([]byte, length)
+				if _, err = io.ReadFull(l.file, payload); err == io.EOF {
+					// the header made it, but none of the payload did
+					err = io.ErrUnexpectedEOF
+				}
+			}
+		}
+		if err == io.EOF {
+			break // clean end of the log, between two records
+		}
+		if err == nil && checksum(header[8:16], payload) != binary.LittleEndian.Uint32(header[4:8]) {
+			err = errors.New("checksum mismatch")
+		}
+		index := binary.LittleEndian.Uint64(header[8:16])
+		if err == nil && index > snapIndex && index != l.index+1 {
+			err = fmt.Errorf("record index %d is out of order", index)
+		}
+		if err != nil {
+			if err == io.ErrUnexpectedEOF {
+				err = errors.New("incomplete record")
+			}
+			slog.Warn("wal: truncating log", "offset", offset, "err", err)
+			if err := l.truncate(offset); err != nil {
+				return nil, err
+			}
+			break
+		}
+
+		offset += headerSize + int64(len(payload))
+		// records from before the last snapshot are already part of it.
+		// they are only still here if we crashed while compacting.
+		if index <= snapIndex {
+			continue
+		}
+		l.index = index
+		records = append(records, payload)
+	}
+
+	if _, err := l.file.Seek(offset, io.SeekStart); err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+func (l *Log) truncate(offset int64) error {
+	if err := l.file.Truncate(offset); err != nil {
+		return err
+	}
+	return l.file.Sync()
+}
+
+// Append writes a record to the end of the log and waits until it is on disk.
+func (l *Log) Append(record []byte) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.file == nil {
+		return os.ErrClosed
+	}
+
+	buf := make([]byte, headerSize+len(record))
+	binary.LittleEndian.PutUint32(buf[0:4], uint32(len(record)))
+	binary.LittleEndian.PutUint64(buf[8:16], l.index+1)
+	copy(buf[headerSize:], record)
+	binary.LittleEndian.PutUint32(buf[4:8], checksum(buf[8:16], record))
+
+	end, err := l.file.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return err
+	}
+	_, err = l.file.Write(buf)
+	if err == nil {
+		err = l.file.Sync()
+	}
+	if err != nil {
+		// cut off whatever part of the record made it to the file,
+		// otherwise the records after it would be thrown away on the next Open.
+		if l.truncate(end) == nil {
+			l.file.Seek(end, io.SeekStart)
+		}
+		return err
+	}
+	l.index++
+	return nil
+}
+
+// Snapshot stores state as the new snapshot and empties the log.
+// state must include the effect of every record appended so far.
+func (l *Log) Snapshot(state []byte) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.file == nil {
+		return os.ErrClosed
+	}
+
+	if err := writeSnapshot(l.dir, l.index, state); err != nil {
+		return err
+	}
+
+	// the snapshot is safe on disk, so the records it covers can go.
+	// if we crash before this, Open skips them using the snapshot index.
+	if err := l.truncate(0); err != nil {
+		return err
+	}
+	_, err := l.file.Seek(0, io.SeekStart)
+	return err
+}
+
+// Close closes the log file. The log can't be used afterwards.
+func (l *Log) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.file == nil {
+		return nil
+	}
+	err := l.file.Close()
+	l.file = nil
+	return err
+}
+
+func checksum(index, payload []byte) uint32 {
+	crc := crc32.Update(0, crcTable, index)
+	return crc32.Update(crc, crcTable, payload)
+}
+
+// the snapshot file is: | crc32 uint32 | index uint64 | state |
+func readSnapshot(path string) (uint64, []byte, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return 0, nil, nil
+	}
+	if err != nil {
+		return 0, nil, err
+	}
+	if len(data) < 12 || checksum(data[4:12], data[12:]) != binary.LittleEndian.Uint32(data[0:4]) {
+		return 0, nil, ErrCorruptSnapshot
+	}
+	return binary.LittleEndian.Uint64(data[4:12]), data[12:], nil
+}
+
+// writeSnapshot writes the snapshot to a temporary file and renames it into place,
+// so a crash leaves either the old or the new snapshot, never half of one.
+func writeSnapshot(dir string, index uint64, state []byte) error {
+	buf := make([]byte, 12+len(state))
+	binary.LittleEndian.PutUint64(buf[4:12], index)
+	copy(buf[12:], state)
+	binary.LittleEndian.PutUint32(buf[0:4], checksum(buf[4:12], state))
+
+	tmp := filepath.Join(dir, snapshotName+".tmp")
+	f, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(buf); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, filepath.Join(dir, snapshotName)); err != nil {
+		return err
+	}
+	return syncDir(dir)
+}
+
+// syncDir makes a rename in dir durable.
+func syncDir(dir string) error {
+	// windows can't open a directory for syncing, and doesn't need it for the rename to stick
+	if runtime.GOOS == "windows" {
+		return nil
+	}
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	return d.Sync()
+}
@@ -0,0 +1,193 @@
+package wal
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// open opens the log in dir and fails the test if it can't.
+func open(t *testing.T, dir string) (*Log, []byte, [][]byte) {
+	t.Helper()
+	l, snapshot, records, err := Open(dir)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	t.Cleanup(func() { l.Close() })
+	return l, snapshot, records
+}
+
+func appendAll(t *testing.T, l *Log, records ...string) {
+	t.Helper()
+	for _, r := range records {
+		if err := l.Append([]byte(r)); err != nil {
+			t.Fatalf("Append(%q): %v", r, err)
+		}
+	}
+}
+
+func texts(records [][]byte) []string {
+	s := make([]string, len(records))
+	for i, r := range records {
+		s[i] = string(r)
+	}
+	return s
+}
+
+// cut keeps the first n bytes of the log file, like a crash in the middle of a write.
+func cut(t *testing.T, dir string, n int64) {
+	t.Helper()
+	if err := os.Truncate(filepath.Join(dir, logName), n); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func logSize(t *testing.T, dir string) int64 {
+	t.Helper()
+	info, err := os.Stat(filepath.Join(dir, logName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return info.Size()
+}
+
+func TestReplay(t *testing.T) {
+	dir := t.TempDir()
+	l, _, _ := open(t, dir)
+	appendAll(t, l, "a", "bb", "")
+	l.Close()
+
+	_, snapshot, records := open(t, dir)
+	if snapshot != nil {
+		t.Errorf("snapshot = %q, want none", snapshot)
+	}
+	if got, want := texts(records), []string{"a", "bb", ""}; !reflect.DeepEqual(got, want) {
+		t.Errorf("records = %q, want %q", got, want)
+	}
+}
+
+func TestTornTail(t *testing.T) {
+	tests := []struct {
+		name string
+		keep int64 // bytes of the last record that made it to the file
+	}{
+		{"torn header", headerSize / 2},
+		{"header without payload", headerSize},
+		{"torn payload", headerSize + 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			l, _, _ := open(t, dir)
+			appendAll(t, l, "first")
+			good := logSize(t, dir)
+			appendAll(t, l, "second")
+			l.Close()
+			cut(t, dir, good+tt.keep)
+
+			l, _, records := open(t, dir)
+			if got, want := texts(records), []string{"first"}; !reflect.DeepEqual(got, want) {
+				t.Fatalf("records = %q, want %q", got, want)
+			}
+			if size := logSize(t, dir); size != good {
+				t.Errorf("log is %d bytes, want it truncated to %d", size, good)
+			}
+
+			// the records after the torn one must survive the next replay
+			appendAll(t, l, "third", "fourth")
+			l.Close()
+			_, _, records = open(t, dir)
+			if got, want := texts(records), []string{"first", "third", "fourth"}; !reflect.DeepEqual(got, want) {
+				t.Errorf("records after appending = %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestBadChecksum(t *testing.T) {
+	dir := t.TempDir()
+	l, _, _ := open(t, dir)
+	appendAll(t, l, "first")
+	good := logSize(t, dir)
+	appendAll(t, l, "second", "third")
+	l.Close()
+
+	// flip a bit in the payload of the second record
+	path := filepath.Join(dir, logName)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data[good+headerSize] ^= 1
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, _, records := open(t, dir)
+	if got, want := texts(records), []string{"first"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("records = %q, want %q", got, want)
+	}
+	if size := logSize(t, dir); size != good {
+		t.Errorf("log is %d bytes, want it truncated to %d", size, good)
+	}
+}
+
+func TestReplayAfterSnapshot(t *testing.T) {
+	dir := t.TempDir()
+	l, _, _ := open(t, dir)
+	appendAll(t, l, "a", "b")
+	if err := l.Snapshot([]byte("state ab")); err != nil {
+		t.Fatalf("Snapshot: %v", err)
+	}
+	appendAll(t, l, "c")
+	l.Close()
+
+	l, snapshot, records := open(t, dir)
+	if string(snapshot) != "state ab" {
+		t.Errorf("snapshot = %q, want %q", snapshot, "state ab")
+	}
+	if got, want := texts(records), []string{"c"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("records = %q, want %q", got, want)
+	}
+	if l.index != 3 {
+		t.Errorf("index = %d, want 3", l.index)
+	}
+}
+
+// A crash after the snapshot is written, but before the log is emptied,
+// leaves records the snapshot already covers. They must not be replayed again.
+func TestCrashBeforeCompaction(t *testing.T) {
+	dir := t.TempDir()
+	l, _, _ := open(t, dir)
+	appendAll(t, l, "a", "b")
+	if err := writeSnapshot(dir, l.index, []byte("state ab")); err != nil {
+		t.Fatal(err)
+	}
+	l.Close()
+
+	l, snapshot, records := open(t, dir)
+	if string(snapshot) != "state ab" {
+		t.Errorf("snapshot = %q, want %q", snapshot, "state ab")
+	}
+	if len(records) != 0 {
+		t.Errorf("records = %q, want none", texts(records))
+	}
+	appendAll(t, l, "c")
+	l.Close()
+
+	_, _, records = open(t, dir)
+	if got, want := texts(records), []string{"c"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("records = %q, want %q", got, want)
+	}
+}
+
+func TestCorruptSnapshot(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, snapshotName), []byte("not a snapshot"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, _, err := Open(dir); err != ErrCorruptSnapshot {
+		t.Errorf("Open = %v, want %v", err, ErrCorruptSnapshot)
+	}
+}